@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"git.sr.ht/~rockorager/vaxis"
+	"git.sr.ht/~rockorager/vaxis/widgets/button"
+	"git.sr.ht/~rockorager/vaxis/widgets/focus"
+	"git.sr.ht/~rockorager/vaxis/widgets/textinput"
+)
+
+type model struct {
+	vx     *vaxis.Vaxis
+	name   *textinput.Model
+	email  *textinput.Model
+	submit *button.Model
+	ring   *focus.Ring
+	result string
+}
+
+func (m *model) Init(vx *vaxis.Vaxis) {
+	m.vx = vx
+	m.name = textinput.New().SetPrompt("Name:  ")
+	m.email = textinput.New().SetPrompt("Email: ")
+	m.submit = button.New("Submit", func() interface{} {
+		m.result = fmt.Sprintf("Hello, %s <%s>", m.name.String(), m.email.String())
+		return nil
+	}, button.FixedWidth(10))
+	m.ring = focus.New(m.name, m.email, m.submit)
+	m.ring.Global = func(key vaxis.Key) bool {
+		return key.MatchString("Ctrl+c")
+	}
+}
+
+func (m *model) Update(ev vaxis.Event) {
+	if m.ring.Update(ev) {
+		return
+	}
+	switch ev := ev.(type) {
+	case vaxis.Key:
+		if ev.MatchString("Ctrl+c") {
+			m.vx.Quit(nil)
+		}
+	}
+}
+
+func (m *model) Draw(win vaxis.Window) {
+	m.ring.Draw(m.name, win.New(0, 0, 40, 1))
+	m.ring.Draw(m.email, win.New(0, 1, 40, 1))
+	m.ring.Draw(m.submit, win.New(0, 3, 10, 1))
+	win.Println(5, vaxis.Segment{Text: m.result})
+}
+
+func main() {
+	err := vaxis.Run(context.Background(), vaxis.Options{}, &model{})
+	if err != nil {
+		panic(err)
+	}
+}
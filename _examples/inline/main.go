@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+
+	"git.sr.ht/~rockorager/vaxis"
+)
+
+func main() {
+	vx, err := vaxis.New(vaxis.Options{
+		Inline:       true,
+		InlineHeight: 6,
+	})
+	if err != nil {
+		panic(err)
+	}
+	defer vx.Close()
+	items := []string{"apple", "banana", "cherry", "date", "elderberry"}
+	selected := 0
+	for ev := range vx.Events() {
+		switch ev := ev.(type) {
+		case vaxis.Key:
+			switch ev.String() {
+			case "Ctrl+c", "Enter":
+				return
+			case "Up", "k":
+				if selected > 0 {
+					selected -= 1
+				}
+			case "Down", "j":
+				if selected < len(items)-1 {
+					selected += 1
+				}
+			}
+		}
+		win := vx.Window()
+		win.Clear()
+		for i, item := range items {
+			style := vaxis.Style{}
+			if i == selected {
+				style.Attribute = vaxis.AttrReverse
+			}
+			win.Println(i, vaxis.Segment{Text: item, Style: style})
+		}
+		win.Println(len(items), vaxis.Segment{
+			Text: fmt.Sprintf("%d/%d", selected+1, len(items)),
+			Style: vaxis.Style{
+				Attribute: vaxis.AttrDim,
+			},
+		})
+		vx.Render()
+	}
+}
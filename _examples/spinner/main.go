@@ -1,33 +1,43 @@
 package main
 
 import (
+	"context"
 	"time"
 
 	"git.sr.ht/~rockorager/vaxis"
 	"git.sr.ht/~rockorager/vaxis/widgets/spinner"
 )
 
+type model struct {
+	vx      *vaxis.Vaxis
+	spinner *spinner.Model
+}
+
+func (m *model) Init(vx *vaxis.Vaxis) {
+	m.vx = vx
+	m.spinner = spinner.New(vx, 100*time.Millisecond)
+	m.spinner.Start()
+}
+
+func (m *model) Update(ev vaxis.Event) {
+	switch ev := ev.(type) {
+	case vaxis.Key:
+		switch ev.String() {
+		case "Ctrl+c":
+			m.vx.Quit(nil)
+		case "space":
+			m.spinner.Toggle()
+		}
+	}
+}
+
+func (m *model) Draw(win vaxis.Window) {
+	m.spinner.Draw(win)
+}
+
 func main() {
-	vx, err := vaxis.New(vaxis.Options{})
+	err := vaxis.Run(context.Background(), vaxis.Options{}, &model{})
 	if err != nil {
 		panic(err)
 	}
-	defer vx.Close()
-	spinner := spinner.New(vx, 100*time.Millisecond)
-	spinner.Start()
-	for ev := range vx.Events() {
-		switch ev := ev.(type) {
-		case vaxis.Key:
-			switch ev.String() {
-			case "Ctrl+c":
-				return
-			case "space":
-				spinner.Toggle()
-			}
-		}
-		win := vx.Window()
-		win.Clear()
-		spinner.Draw(win)
-		vx.Render()
-	}
 }
@@ -49,6 +49,13 @@ type Parser struct {
 	// escape sequence
 	escTimeout *time.Timer
 	mu         sync.Mutex
+	// escDelay is the duration of escTimeout
+	escDelay time.Duration
+	// x10Mouse is set when CSI M is followed by the three bytes of an X10
+	// mouse report
+	x10Mouse bool
+	// x10 holds the bytes of an X10 mouse report
+	x10 []rune
 
 	oscData []rune
 	apcData []rune
@@ -56,7 +63,23 @@ type Parser struct {
 	dcs DCS
 }
 
+// DefaultEscTimeout is how long a Parser waits for the rest of an escape
+// sequence by default
+const DefaultEscTimeout = 10 * time.Millisecond
+
 func NewParser(r io.Reader) *Parser {
+	return newParser(r, false)
+}
+
+// NewInputParser creates a Parser for input from a terminal. In addition to
+// the sequences of NewParser, the three bytes following CSI M are read as an
+// X10 mouse report, which is emitted in the URXVT form CSI Cb ; Cx ; Cy M. In
+// output, CSI M is Delete Line, so this is only for input
+func NewInputParser(r io.Reader) *Parser {
+	return newParser(r, true)
+}
+
+func newParser(r io.Reader, x10Mouse bool) *Parser {
 	parser := &Parser{
 		close:            make(chan bool, 1),
 		closed:           make(chan bool, 1),
@@ -66,6 +89,8 @@ func NewParser(r io.Reader) *Parser {
 		paramListPool:    newPool(newCSIParamList),
 		paramPool:        newPool(newCSIParam),
 		intermediatePool: newPool(newIntermediateSlice),
+		escDelay:         DefaultEscTimeout,
+		x10Mouse:         x10Mouse,
 	}
 	// Rob Pike didn't use concurrency since he wanted templates to be able
 	// to happen in init() functions, but we don't care about that.
@@ -87,6 +112,16 @@ func (p *Parser) Next() chan Sequence {
 	return p.sequences
 }
 
+// SetEscTimeout sets how long the parser waits for the rest of an escape
+// sequence after an Esc before emitting the Esc by itself. A longer timeout
+// helps over slow connections, where sequences can be split, at the cost of a
+// slower Escape key
+func (p *Parser) SetEscTimeout(d time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.escDelay = d
+}
+
 func (p *Parser) Finish(seq Sequence) {
 	switch seq := seq.(type) {
 	case ESC:
@@ -462,7 +497,7 @@ func anywhere(r rune, p *Parser) stateFn {
 			p.exit = nil
 		}
 		p.clear()
-		p.escTimeout = time.AfterFunc(10*time.Millisecond, func() {
+		p.escTimeout = time.AfterFunc(p.escDelay, func() {
 			p.emit(C0(0x1B))
 			p.mu.Lock()
 			p.state = ground
@@ -513,6 +548,9 @@ func csiEntry(r rune, p *Parser) stateFn {
 	case in(r, 0x20, 0x2F):
 		p.collect(r)
 		return csiIntermediate
+	case r == 'M' && p.x10Mouse:
+		p.x10 = p.x10[:0]
+		return x10Mouse
 	case in(r, 0x40, 0x7E):
 		p.csiDispatch(r)
 		return ground
@@ -523,6 +561,27 @@ func csiEntry(r rune, p *Parser) stateFn {
 	}
 }
 
+// This state is entered after CSI M when decoding X10 mouse reports. The
+// button and coordinates follow as three bytes, each offset by 32. Invalid
+// UTF-8 is read byte by byte, so the bytes may be raw or UTF-8 encoded (mode
+// 1005). The report is emitted as CSI Cb ; Cx ; Cy M, the same as URXVT
+// reports
+func x10Mouse(r rune, p *Parser) stateFn {
+	p.x10 = append(p.x10, r)
+	if len(p.x10) < 3 {
+		return x10Mouse
+	}
+	p.emit(CSI{
+		Final: 'M',
+		Parameters: [][]int{
+			{int(p.x10[0])},
+			{int(p.x10[1]) - 32},
+			{int(p.x10[2]) - 32},
+		},
+	})
+	return ground
+}
+
 // This state is entered when a parameter character is recognised in a
 // control sequence. It then recognises other parameter characters until an
 // intermediate or final character appears. Further occurrences of the
@@ -2,9 +2,11 @@ package ansi
 
 import (
 	"bytes"
+	"io"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -820,3 +822,90 @@ func TestOSC(t *testing.T) {
 		})
 	}
 }
+
+func TestEscTimeout(t *testing.T) {
+	r, w := io.Pipe()
+	parse := NewParser(r)
+	parse.SetEscTimeout(200 * time.Millisecond)
+	defer w.Close()
+
+	// The rest of the sequence arrives within the timeout
+	_, _ = w.Write([]byte("\x1b"))
+	time.Sleep(20 * time.Millisecond)
+	_, _ = w.Write([]byte("[A"))
+	seq := <-parse.Next()
+	csi, ok := seq.(CSI)
+	assert.True(t, ok, "got %#v", seq)
+	assert.Equal(t, 'A', csi.Final)
+	parse.Finish(seq)
+
+	// A lone Esc is emitted after the timeout
+	_, _ = w.Write([]byte("\x1b"))
+	assert.Equal(t, C0(0x1B), <-parse.Next())
+}
+
+func TestX10Mouse(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []Sequence
+	}{
+		{
+			name:  "press",
+			input: "\x1b[M !!",
+			expected: []Sequence{
+				CSI{Final: 'M', Parameters: [][]int{{32}, {1}, {1}}},
+			},
+		},
+		{
+			name:  "raw bytes",
+			input: "\x1b[M#\xff\x80",
+			expected: []Sequence{
+				CSI{Final: 'M', Parameters: [][]int{{35}, {223}, {96}}},
+			},
+		},
+		{
+			name:  "utf-8 coordinates",
+			input: "\x1b[M`ƐĠ",
+			expected: []Sequence{
+				CSI{Final: 'M', Parameters: [][]int{{96}, {368}, {256}}},
+			},
+		},
+		{
+			name:  "followed by text",
+			input: "\x1b[Ma!\"j",
+			expected: []Sequence{
+				CSI{Final: 'M', Parameters: [][]int{{97}, {1}, {2}}},
+				Print{"j", 1},
+			},
+		},
+		{
+			name:  "urxvt",
+			input: "\x1b[32;10;20M",
+			expected: []Sequence{
+				CSI{Final: 'M', Parameters: [][]int{{32}, {10}, {20}}},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			parse := NewInputParser(strings.NewReader(test.input))
+			i := 0
+			for seq := range parse.Next() {
+				if _, ok := seq.(EOF); ok {
+					break
+				}
+				if i < len(test.expected) {
+					assert.Equal(t, test.expected[i], seq)
+				}
+				i += 1
+			}
+			assert.Equal(t, len(test.expected), i, "wrong amount of sequences")
+		})
+	}
+
+	// Without X10 decoding, CSI M is Delete Line
+	parse := NewParser(strings.NewReader("\x1b[Mj"))
+	assert.Equal(t, CSI{Final: 'M'}, <-parse.Next())
+	assert.Equal(t, Print{"j", 1}, <-parse.Next())
+}
@@ -0,0 +1,131 @@
+package vaxis
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Capabilities are the terminal features Vaxis detected at startup, after any
+// overrides from [Options] have been applied
+type Capabilities struct {
+	// RGB colors can be displayed. Without RGB, colors are converted to the
+	// nearest palette index
+	RGB bool
+	// StyledUnderlines, such as curly and dotted underlines, and underline
+	// colors can be displayed
+	StyledUnderlines bool
+	// SynchronizedUpdates are used to prevent tearing while rendering
+	SynchronizedUpdates bool
+	// UnicodeCore is used to measure the width of graphemes
+	UnicodeCore bool
+	// KittyKeyboard is the kitty keyboard protocol
+	KittyKeyboard bool
+	// ColorThemeUpdates are reported when the terminal theme changes
+	ColorThemeUpdates bool
+	// PixelSize is set when the terminal reports its size in characters and
+	// pixels in band. Otherwise the size is read from the kernel, which may
+	// not report the pixel size
+	PixelSize bool
+	// MousePixels is set when the terminal can report the position of mouse
+	// events in pixels (SGR-Pixels)
+	MousePixels bool
+}
+
+// CapabilityOverride forces a capability on or off, regardless of what was
+// detected. The zero value uses the detected value. A CapabilityOverride can
+// be read from a config file as "detect", "on" or "off"
+type CapabilityOverride int
+
+const (
+	// CapabilityDetect uses the detected value
+	CapabilityDetect CapabilityOverride = iota
+	// CapabilityOn forces the capability on
+	CapabilityOn
+	// CapabilityOff forces the capability off
+	CapabilityOff
+)
+
+func (o CapabilityOverride) String() string {
+	switch o {
+	case CapabilityOn:
+		return "on"
+	case CapabilityOff:
+		return "off"
+	default:
+		return "detect"
+	}
+}
+
+// MarshalText implements [encoding.TextMarshaler]
+func (o CapabilityOverride) MarshalText() ([]byte, error) {
+	return []byte(o.String()), nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler]. "on", "true", "off",
+// "false", "detect" and the empty string are accepted, ignoring case
+func (o *CapabilityOverride) UnmarshalText(text []byte) error {
+	switch strings.ToLower(string(text)) {
+	case "", "detect":
+		*o = CapabilityDetect
+	case "on", "true":
+		*o = CapabilityOn
+	case "off", "false":
+		*o = CapabilityOff
+	default:
+		return fmt.Errorf("vaxis: invalid capability override: %q", text)
+	}
+	return nil
+}
+
+// apply returns the value of a capability after the override
+func (o CapabilityOverride) apply(detected bool) bool {
+	switch o {
+	case CapabilityOn:
+		return true
+	case CapabilityOff:
+		return false
+	default:
+		return detected
+	}
+}
+
+// CapabilityOverrides force capabilities on or off. Forcing a capability on
+// which the terminal doesn't support will likely result in garbled output
+type CapabilityOverrides struct {
+	RGB                 CapabilityOverride
+	StyledUnderlines    CapabilityOverride
+	SynchronizedUpdates CapabilityOverride
+	UnicodeCore         CapabilityOverride
+	KittyKeyboard       CapabilityOverride
+	ColorThemeUpdates   CapabilityOverride
+	PixelSize           CapabilityOverride
+	MousePixels         CapabilityOverride
+}
+
+// apply applies the overrides to the detected capabilities
+func (o CapabilityOverrides) apply(caps *capabilities) {
+	caps.rgb = o.RGB.apply(caps.rgb)
+	caps.styledUnderlines = o.StyledUnderlines.apply(caps.styledUnderlines)
+	caps.synchronizedUpdate = o.SynchronizedUpdates.apply(caps.synchronizedUpdate)
+	caps.unicodeCore = o.UnicodeCore.apply(caps.unicodeCore)
+	caps.kittyKeyboard = o.KittyKeyboard.apply(caps.kittyKeyboard)
+	caps.colorThemeUpdates = o.ColorThemeUpdates.apply(caps.colorThemeUpdates)
+	pixelSize := o.PixelSize.apply(caps.reportSizeChars && caps.reportSizePixels)
+	caps.reportSizeChars = pixelSize
+	caps.reportSizePixels = pixelSize
+	caps.sgrPixels = o.MousePixels.apply(caps.sgrPixels)
+}
+
+// Capabilities returns the capabilities of the terminal
+func (vx *Vaxis) Capabilities() Capabilities {
+	return Capabilities{
+		RGB:                 vx.caps.rgb,
+		StyledUnderlines:    vx.caps.styledUnderlines,
+		SynchronizedUpdates: vx.caps.synchronizedUpdate,
+		UnicodeCore:         vx.caps.unicodeCore,
+		KittyKeyboard:       vx.caps.kittyKeyboard,
+		ColorThemeUpdates:   vx.caps.colorThemeUpdates,
+		PixelSize:           vx.caps.reportSizeChars && vx.caps.reportSizePixels,
+		MousePixels:         vx.caps.sgrPixels,
+	}
+}
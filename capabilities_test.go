@@ -0,0 +1,56 @@
+package vaxis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCapabilityOverrides(t *testing.T) {
+	caps := capabilities{
+		rgb:              true,
+		styledUnderlines: true,
+		reportSizeChars:  true,
+		reportSizePixels: true,
+	}
+	CapabilityOverrides{
+		RGB:           CapabilityOff,
+		KittyKeyboard: CapabilityOn,
+		PixelSize:     CapabilityOff,
+		MousePixels:   CapabilityOn,
+	}.apply(&caps)
+	vx := &Vaxis{caps: caps}
+	assert.Equal(t, Capabilities{
+		StyledUnderlines: true,
+		KittyKeyboard:    true,
+		MousePixels:      true,
+	}, vx.Capabilities())
+}
+
+func TestCapabilityOverrideText(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected CapabilityOverride
+	}{
+		{"", CapabilityDetect},
+		{"detect", CapabilityDetect},
+		{"on", CapabilityOn},
+		{"True", CapabilityOn},
+		{"OFF", CapabilityOff},
+		{"false", CapabilityOff},
+	}
+	for _, test := range tests {
+		t.Run(test.text, func(t *testing.T) {
+			var o CapabilityOverride
+			assert.NoError(t, o.UnmarshalText([]byte(test.text)))
+			assert.Equal(t, test.expected, o)
+		})
+	}
+
+	var o CapabilityOverride
+	assert.Error(t, o.UnmarshalText([]byte("maybe")))
+
+	text, err := CapabilityOff.MarshalText()
+	assert.NoError(t, err)
+	assert.Equal(t, "off", string(text))
+}
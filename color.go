@@ -1,7 +1,5 @@
 package vaxis
 
-import "math"
-
 // Color is a terminal color. The zero value represents the default foreground
 // or background color
 type Color uint32
@@ -26,47 +24,6 @@ func (c Color) Params() []uint8 {
 	return []uint8{}
 }
 
-// asIndex returns an 8bit color index for a given color. If the color is the
-// default color or already an index color, this returns itself. RGB colors will
-// be converted to their closest 256-index match, excluding indexes 0-15 as
-// these are typically user altered
-func (c Color) asIndex() Color {
-	if c&rgb == 0 {
-		return c
-	}
-	// Convert to 256 palette
-	oR := uint8(c >> 16)
-	oG := uint8(c >> 8)
-	oB := uint8(c)
-	dist := math.Inf(1)
-	match := -1
-	for i, v := range colorIndex {
-		dR := uint8(v >> 16)
-		dG := uint8(v >> 8)
-		dB := uint8(v)
-		// weighted, thanks stackoverflow. We skip the sqrt
-		// because we don't care about the absolute value of the
-		// distance, only the comparisons
-		trial := sq(float64(dR-oR)*.3) + sq(float64(dG-oG)*.59) + sq(float64(dB-oB)*.11)
-		if trial < dist {
-			match = i
-			dist = trial
-		}
-		if dist == 0 {
-			// return early when we have an exact match
-			return IndexColor(uint8(i + 16))
-		}
-	}
-	if match < 0 {
-		return Color(0)
-	}
-	return IndexColor(uint8(match + 16))
-}
-
-func sq(v float64) float64 {
-	return v * v
-}
-
 // RGBColor creates a new Color based on the supplied RGB values
 func RGBColor(r uint8, g uint8, b uint8) Color {
 	color := Color(int(r)<<16 | int(g)<<8 | int(b))
@@ -0,0 +1,195 @@
+package vaxis
+
+// ColorDepth is the number of colors used when rendering. Colors which can't
+// be displayed at the depth are converted to the closest color which can
+type ColorDepth int
+
+const (
+	// ColorDepthAuto detects the color depth from the terminal's
+	// capabilities, its terminfo entry and NO_COLOR
+	ColorDepthAuto ColorDepth = iota
+	// ColorDepthTrueColor displays RGB colors as is
+	ColorDepthTrueColor
+	// ColorDepth256 uses the 256 color palette
+	ColorDepth256
+	// ColorDepth16 uses the 16 ANSI colors, including the bright colors
+	ColorDepth16
+	// ColorDepth8 uses the 8 ANSI colors
+	ColorDepth8
+	// ColorDepthMonochrome never sets colors. Only attributes are used,
+	// and cells with a background color are displayed in reverse video
+	ColorDepthMonochrome
+)
+
+func (d ColorDepth) String() string {
+	switch d {
+	case ColorDepthTrueColor:
+		return "truecolor"
+	case ColorDepth256:
+		return "256"
+	case ColorDepth16:
+		return "16"
+	case ColorDepth8:
+		return "8"
+	case ColorDepthMonochrome:
+		return "monochrome"
+	default:
+		return "auto"
+	}
+}
+
+// defaultPalette is the xterm default for the 16 ANSI colors, used to quantize
+// colors when we don't know the terminal's palette
+var defaultPalette = [16]uint32{
+	0x000000,
+	0xCD0000,
+	0x00CD00,
+	0xCDCD00,
+	0x0000EE,
+	0xCD00CD,
+	0x00CDCD,
+	0xE5E5E5,
+	0x7F7F7F,
+	0xFF0000,
+	0x00FF00,
+	0xFFFF00,
+	0x5C5CFF,
+	0xFF00FF,
+	0x00FFFF,
+	0xFFFFFF,
+}
+
+// detectColorDepth determines the color depth. An explicit depth always wins,
+// then NO_COLOR, then the terminal's capabilities
+func detectColorDepth(depth ColorDepth, caps capabilities, getenv func(string) string) ColorDepth {
+	if depth != ColorDepthAuto {
+		return depth
+	}
+	if getenv("NO_COLOR") != "" {
+		return ColorDepthMonochrome
+	}
+	switch {
+	case caps.rgb:
+		return ColorDepthTrueColor
+	case caps.colors == 0, caps.colors >= 256:
+		// Without terminfo, we assume the 256 color palette as
+		// nearly every terminal supports it
+		return ColorDepth256
+	case caps.colors >= 16:
+		return ColorDepth16
+	case caps.colors >= 8:
+		return ColorDepth8
+	default:
+		return ColorDepthMonochrome
+	}
+}
+
+// ColorDepth returns the color depth used when rendering
+func (vx *Vaxis) ColorDepth() ColorDepth {
+	if vx.colorDepth != ColorDepthAuto {
+		return vx.colorDepth
+	}
+	if vx.caps.rgb {
+		return ColorDepthTrueColor
+	}
+	return ColorDepth256
+}
+
+// indexRGB returns the RGB value of a palette index. Colors set with
+// SetPaletteColor are used first. The 16 ANSI colors are taken from the
+// terminal's palette if it is known, and the xterm defaults if not
+func (vx *Vaxis) indexRGB(i int) uint32 {
+	switch {
+	case vx.paletteColors[i] != 0:
+		return uint32(vx.paletteColors[i]) & 0xFFFFFF
+	case i < len(vx.palette):
+		return vx.palette[i]
+	case i < 16:
+		return defaultPalette[i]
+	default:
+		return colorIndex[i-16]
+	}
+}
+
+// quantize converts a color to the closest color which can be displayed at
+// depth. The default color is always returned as is
+func (vx *Vaxis) quantize(c Color, depth ColorDepth) Color {
+	if c == 0 {
+		return c
+	}
+	switch depth {
+	case ColorDepthTrueColor:
+		return c
+	case ColorDepth256:
+		if c&rgb == 0 {
+			return c
+		}
+		// Indexes 0-15 are typically altered by the user, so we only
+		// use them if we know what they are
+		lo := 16
+		if len(vx.palette) >= 16 {
+			lo = 0
+		}
+		return IndexColor(vx.nearestIndex(uint32(c), lo, 256))
+	case ColorDepth16, ColorDepth8:
+		n := 16
+		if depth == ColorDepth8 {
+			n = 8
+		}
+		if c&indexed != 0 {
+			i := int(uint8(c))
+			switch {
+			case i < n:
+				return c
+			case i < 16:
+				// The bright colors are the same hues as the
+				// regular colors
+				return IndexColor(uint8(i - 8))
+			}
+			return IndexColor(vx.nearestIndex(vx.indexRGB(i), 0, n))
+		}
+		return IndexColor(vx.nearestIndex(uint32(c), 0, n))
+	default:
+		return 0
+	}
+}
+
+// monochrome returns the style displayed at ColorDepthMonochrome. The colors
+// are dropped, and cells with a background color are reversed instead, so that
+// selections and status lines still stand out
+func monochrome(style Style) Style {
+	if style.Background != 0 {
+		style.Attribute |= AttrReverse
+	}
+	style.Foreground = 0
+	style.Background = 0
+	style.UnderlineColor = 0
+	return style
+}
+
+// nearestIndex returns the palette index in [lo, hi) closest to the RGB value
+func (vx *Vaxis) nearestIndex(v uint32, lo int, hi int) uint8 {
+	match := lo
+	dist := -1
+	for i := lo; i < hi; i += 1 {
+		d := colorDistance(v, vx.indexRGB(i))
+		if dist < 0 || d < dist {
+			match = i
+			dist = d
+		}
+		if dist == 0 {
+			break
+		}
+	}
+	return uint8(match)
+}
+
+// colorDistance is the squared distance between two RGB values, weighted for
+// how sensitive we are to each channel. We skip the square root as we only
+// compare distances
+func colorDistance(a uint32, b uint32) int {
+	dR := int(uint8(a>>16)) - int(uint8(b>>16))
+	dG := int(uint8(a>>8)) - int(uint8(b>>8))
+	dB := int(uint8(a)) - int(uint8(b))
+	return dR*dR*30 + dG*dG*59 + dB*dB*11
+}
@@ -0,0 +1,117 @@
+package vaxis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectColorDepth(t *testing.T) {
+	tests := []struct {
+		name     string
+		depth    ColorDepth
+		caps     capabilities
+		noColor  string
+		expected ColorDepth
+	}{
+		{
+			name:     "RGB",
+			caps:     capabilities{rgb: true},
+			expected: ColorDepthTrueColor,
+		},
+		{
+			name:     "unknown",
+			expected: ColorDepth256,
+		},
+		{
+			name:     "terminfo 256",
+			caps:     capabilities{colors: 256},
+			expected: ColorDepth256,
+		},
+		{
+			name:     "terminfo 16",
+			caps:     capabilities{colors: 16},
+			expected: ColorDepth16,
+		},
+		{
+			name:     "terminfo 8",
+			caps:     capabilities{colors: 8},
+			expected: ColorDepth8,
+		},
+		{
+			name:     "terminfo 2",
+			caps:     capabilities{colors: 2},
+			expected: ColorDepthMonochrome,
+		},
+		{
+			name:     "NO_COLOR",
+			caps:     capabilities{rgb: true},
+			noColor:  "1",
+			expected: ColorDepthMonochrome,
+		},
+		{
+			name:     "explicit depth wins over NO_COLOR",
+			depth:    ColorDepth16,
+			caps:     capabilities{rgb: true},
+			noColor:  "1",
+			expected: ColorDepth16,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			getenv := func(key string) string {
+				if key == "NO_COLOR" {
+					return test.noColor
+				}
+				return ""
+			}
+			assert.Equal(t, test.expected, detectColorDepth(test.depth, test.caps, getenv))
+		})
+	}
+}
+
+func TestQuantize(t *testing.T) {
+	tests := []struct {
+		name     string
+		depth    ColorDepth
+		color    Color
+		expected Color
+	}{
+		{"truecolor", ColorDepthTrueColor, RGBColor(1, 2, 3), RGBColor(1, 2, 3)},
+		{"default", ColorDepth8, 0, 0},
+		{"256 exact", ColorDepth256, HexColor(0xFF0000), IndexColor(196)},
+		{"256 gray", ColorDepth256, HexColor(0x303131), IndexColor(236)},
+		{"256 skips ANSI colors", ColorDepth256, HexColor(0xCD0000), IndexColor(160)},
+		{"256 index", ColorDepth256, IndexColor(3), IndexColor(3)},
+		{"16 RGB", ColorDepth16, HexColor(0xF01010), IndexColor(9)},
+		{"16 cube", ColorDepth16, IndexColor(21), IndexColor(4)},
+		{"16 gray", ColorDepth16, IndexColor(244), IndexColor(8)},
+		{"16 ANSI", ColorDepth16, IndexColor(12), IndexColor(12)},
+		{"8 bright", ColorDepth8, IndexColor(12), IndexColor(4)},
+		{"8 RGB", ColorDepth8, HexColor(0xF01010), IndexColor(1)},
+		{"8 white", ColorDepth8, HexColor(0xFFFFFF), IndexColor(7)},
+		{"monochrome", ColorDepthMonochrome, RGBColor(1, 2, 3), 0},
+	}
+	vx := &Vaxis{}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, vx.quantize(test.color, test.depth))
+		})
+	}
+}
+
+func TestQuantizePalette(t *testing.T) {
+	// A solarized palette, which is far from the xterm defaults
+	vx := &Vaxis{
+		palette: []uint32{
+			0x073642, 0xDC322F, 0x859900, 0xB58900,
+			0x268BD2, 0xD33682, 0x2AA198, 0xEEE8D5,
+			0x002B36, 0xCB4B16, 0x586E75, 0x657B83,
+			0x839496, 0x6C71C4, 0x93A1A1, 0xFDF6E3,
+		},
+	}
+	assert.Equal(t, IndexColor(4), vx.quantize(HexColor(0x268BD2), ColorDepth16))
+	assert.Equal(t, IndexColor(13), vx.quantize(HexColor(0x6A6FC0), ColorDepth16))
+	// With a known palette, the ANSI colors are used for 256 colors too
+	assert.Equal(t, IndexColor(2), vx.quantize(HexColor(0x859900), ColorDepth256))
+}
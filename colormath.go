@@ -0,0 +1,241 @@
+package vaxis
+
+import "math"
+
+// IsDefault reports if the color is the terminal's default color
+func (c Color) IsDefault() bool {
+	return c&(indexed|rgb) == 0
+}
+
+// IsIndex reports if the color is a palette index
+func (c Color) IsIndex() bool {
+	return c&indexed != 0
+}
+
+// IsRGB reports if the color is an RGB color
+func (c Color) IsRGB() bool {
+	return c&rgb != 0
+}
+
+// RGB returns the red, green and blue values of the color. Palette indexes
+// are resolved with the xterm default palette, which may differ from the
+// terminal's palette. The default color has no RGB value: it is treated as
+// black by RGB and the color math built on it
+func (c Color) RGB() (r uint8, g uint8, b uint8) {
+	var v uint32
+	switch {
+	case c.IsRGB():
+		v = uint32(c)
+	case c.IsIndex():
+		i := int(uint8(c))
+		if i < 16 {
+			v = defaultPalette[i]
+		} else {
+			v = colorIndex[i-16]
+		}
+	}
+	return uint8(v >> 16), uint8(v >> 8), uint8(v)
+}
+
+// Blend mixes the color with other by linearly interpolating each channel. A
+// t of 0 is the color, and 1 is other
+func (c Color) Blend(other Color, t float64) Color {
+	r1, g1, b1 := c.RGB()
+	r2, g2, b2 := other.RGB()
+	mix := func(a uint8, b uint8) uint8 {
+		return channel((float64(a) + (float64(b)-float64(a))*t) / 255)
+	}
+	return RGBColor(mix(r1, r2), mix(g1, g2), mix(b1, b2))
+}
+
+// BlendPerceptual mixes the color with other in the OKLab color space, which
+// changes lightness and hue evenly to the eye. A t of 0 is the color, and 1 is
+// other
+func (c Color) BlendPerceptual(other Color, t float64) Color {
+	l1, a1, b1 := c.oklab()
+	l2, a2, b2 := other.oklab()
+	return oklabColor(
+		l1+(l2-l1)*t,
+		a1+(a2-a1)*t,
+		b1+(b2-b1)*t,
+	)
+}
+
+// Gradient returns n colors evenly spaced along the stops, blended
+// perceptually. The first and last colors are the first and last stops
+func Gradient(n int, stops ...Color) []Color {
+	if n <= 0 || len(stops) == 0 {
+		return nil
+	}
+	colors := make([]Color, n)
+	if len(stops) == 1 || n == 1 {
+		for i := range colors {
+			colors[i] = stops[0]
+		}
+		return colors
+	}
+	segments := len(stops) - 1
+	for i := range colors {
+		pos := float64(i) / float64(n-1) * float64(segments)
+		seg := int(pos)
+		if seg >= segments {
+			seg = segments - 1
+		}
+		colors[i] = stops[seg].BlendPerceptual(stops[seg+1], pos-float64(seg))
+	}
+	return colors
+}
+
+// HSL returns the hue in degrees [0, 360), and the saturation and lightness in
+// [0, 1]
+func (c Color) HSL() (h float64, s float64, l float64) {
+	r8, g8, b8 := c.RGB()
+	r := float64(r8) / 255
+	g := float64(g8) / 255
+	b := float64(b8) / 255
+	hi := math.Max(r, math.Max(g, b))
+	lo := math.Min(r, math.Min(g, b))
+	l = (hi + lo) / 2
+	d := hi - lo
+	if d == 0 {
+		return 0, 0, l
+	}
+	s = d / (1 - math.Abs(2*l-1))
+	switch hi {
+	case r:
+		h = math.Mod((g-b)/d, 6)
+	case g:
+		h = (b-r)/d + 2
+	default:
+		h = (r-g)/d + 4
+	}
+	return normalizeHue(h * 60), s, l
+}
+
+// HSLColor creates a Color from a hue in degrees, and a saturation and
+// lightness in [0, 1]
+func HSLColor(h float64, s float64, l float64) Color {
+	h = normalizeHue(h) / 60
+	chroma := (1 - math.Abs(2*l-1)) * s
+	x := chroma * (1 - math.Abs(math.Mod(h, 2)-1))
+	var r, g, b float64
+	switch int(h) {
+	case 0:
+		r, g = chroma, x
+	case 1:
+		r, g = x, chroma
+	case 2:
+		g, b = chroma, x
+	case 3:
+		g, b = x, chroma
+	case 4:
+		r, b = x, chroma
+	default:
+		r, b = chroma, x
+	}
+	m := l - chroma/2
+	return RGBColor(channel(r+m), channel(g+m), channel(b+m))
+}
+
+// OKLCH returns the color in the OKLCH color space: the perceived lightness
+// in [0, 1], the chroma, and the hue in degrees [0, 360)
+func (c Color) OKLCH() (l float64, chroma float64, h float64) {
+	l, a, b := c.oklab()
+	chroma = math.Hypot(a, b)
+	if chroma < 1e-6 {
+		return l, 0, 0
+	}
+	return l, chroma, normalizeHue(math.Atan2(b, a) * 180 / math.Pi)
+}
+
+// OKLCHColor creates a Color from the OKLCH color space. Colors outside of the
+// sRGB gamut are clipped
+func OKLCHColor(l float64, chroma float64, h float64) Color {
+	rad := h * math.Pi / 180
+	return oklabColor(l, chroma*math.Cos(rad), chroma*math.Sin(rad))
+}
+
+// Luminance returns the relative luminance of the color as defined by WCAG,
+// from 0 for black to 1 for white
+func (c Color) Luminance() float64 {
+	r, g, b := c.RGB()
+	return 0.2126*linearize(r) + 0.7152*linearize(g) + 0.0722*linearize(b)
+}
+
+// Contrast returns the WCAG contrast ratio between the color and other, from 1
+// to 21. WCAG recommends at least 4.5 for text
+func (c Color) Contrast(other Color) float64 {
+	l1 := c.Luminance()
+	l2 := other.Luminance()
+	if l1 < l2 {
+		l1, l2 = l2, l1
+	}
+	return (l1 + 0.05) / (l2 + 0.05)
+}
+
+// linearize converts an sRGB channel to linear light
+func linearize(v uint8) float64 {
+	f := float64(v) / 255
+	if f <= 0.04045 {
+		return f / 12.92
+	}
+	return math.Pow((f+0.055)/1.055, 2.4)
+}
+
+// delinearize converts linear light to an sRGB channel in [0, 1]
+func delinearize(f float64) float64 {
+	if f <= 0.0031308 {
+		return f * 12.92
+	}
+	return 1.055*math.Pow(f, 1/2.4) - 0.055
+}
+
+// channel converts a value in [0, 1] to an 8 bit channel, clipping values out
+// of range
+func channel(f float64) uint8 {
+	return uint8(math.Round(math.Max(0, math.Min(1, f)) * 255))
+}
+
+// normalizeHue wraps a hue in degrees to [0, 360)
+func normalizeHue(h float64) float64 {
+	h = math.Mod(h, 360)
+	if h < 0 {
+		h += 360
+	}
+	return h
+}
+
+// oklab converts the color to the OKLab color space. See
+// https://bottosson.github.io/posts/oklab/
+func (c Color) oklab() (l float64, a float64, b float64) {
+	r8, g8, b8 := c.RGB()
+	r := linearize(r8)
+	g := linearize(g8)
+	bl := linearize(b8)
+	lms := [3]float64{
+		math.Cbrt(0.4122214708*r + 0.5363325363*g + 0.0514459929*bl),
+		math.Cbrt(0.2119034982*r + 0.6806995451*g + 0.1073969566*bl),
+		math.Cbrt(0.0883024619*r + 0.2817188376*g + 0.6299787005*bl),
+	}
+	l = 0.2104542553*lms[0] + 0.7936177850*lms[1] - 0.0040720468*lms[2]
+	a = 1.9779984951*lms[0] - 2.4285922050*lms[1] + 0.4505937099*lms[2]
+	b = 0.0259040371*lms[0] + 0.7827717662*lms[1] - 0.8086757660*lms[2]
+	return l, a, b
+}
+
+// oklabColor creates a Color from the OKLab color space, clipping colors
+// outside of the sRGB gamut
+func oklabColor(l float64, a float64, b float64) Color {
+	lc := l + 0.3963377774*a + 0.2158037573*b
+	mc := l - 0.1055613458*a - 0.0638541728*b
+	sc := l - 0.0894841775*a - 1.2914855480*b
+	lc, mc, sc = lc*lc*lc, mc*mc*mc, sc*sc*sc
+	r := 4.0767416621*lc - 3.3077115913*mc + 0.2309699292*sc
+	g := -1.2684380046*lc + 2.6097574011*mc - 0.3413193965*sc
+	bl := -0.0041960863*lc - 0.7034186147*mc + 1.7076147010*sc
+	return RGBColor(
+		channel(delinearize(r)),
+		channel(delinearize(g)),
+		channel(delinearize(bl)),
+	)
+}
@@ -0,0 +1,125 @@
+package vaxis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestColorKind(t *testing.T) {
+	assert.True(t, Color(0).IsDefault())
+	assert.False(t, Color(0).IsIndex())
+	assert.False(t, Color(0).IsRGB())
+	assert.True(t, IndexColor(0).IsIndex())
+	assert.False(t, IndexColor(0).IsDefault())
+	assert.True(t, RGBColor(0, 0, 0).IsRGB())
+	assert.False(t, RGBColor(0, 0, 0).IsDefault())
+}
+
+func TestColorRGB(t *testing.T) {
+	tests := []struct {
+		name  string
+		color Color
+		rgb   [3]uint8
+	}{
+		{name: "rgb", color: HexColor(0x123456), rgb: [3]uint8{0x12, 0x34, 0x56}},
+		{name: "ansi", color: IndexColor(1), rgb: [3]uint8{0xCD, 0, 0}},
+		{name: "cube", color: IndexColor(16), rgb: [3]uint8{0, 0, 0}},
+		{name: "grayscale", color: IndexColor(255), rgb: [3]uint8{0xEE, 0xEE, 0xEE}},
+		{name: "default", color: 0, rgb: [3]uint8{0, 0, 0}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r, g, b := test.color.RGB()
+			assert.Equal(t, test.rgb, [3]uint8{r, g, b})
+		})
+	}
+}
+
+func TestBlend(t *testing.T) {
+	black := HexColor(0x000000)
+	white := HexColor(0xFFFFFF)
+	assert.Equal(t, black, black.Blend(white, 0))
+	assert.Equal(t, white, black.Blend(white, 1))
+	assert.Equal(t, HexColor(0x808080), black.Blend(white, 0.5))
+	// Indexed colors blend through their RGB value
+	assert.Equal(t, HexColor(0x670000), IndexColor(1).Blend(black, 0.5))
+
+	assert.Equal(t, black, black.BlendPerceptual(white, 0))
+	assert.Equal(t, white, black.BlendPerceptual(white, 1))
+	// Perceptual middle gray is lighter than the numeric middle
+	assert.Equal(t, HexColor(0x636363), black.BlendPerceptual(white, 0.5))
+}
+
+func TestGradient(t *testing.T) {
+	red := HexColor(0xFF0000)
+	blue := HexColor(0x0000FF)
+	white := HexColor(0xFFFFFF)
+	assert.Nil(t, Gradient(0, red, blue))
+	assert.Equal(t, []Color{red, red}, Gradient(2, red))
+	assert.Equal(t, []Color{red}, Gradient(1, red, blue))
+
+	colors := Gradient(5, red, blue, white)
+	assert.Len(t, colors, 5)
+	assert.Equal(t, red, colors[0])
+	assert.Equal(t, blue, colors[2])
+	assert.Equal(t, white, colors[4])
+}
+
+func TestHSL(t *testing.T) {
+	tests := []struct {
+		name  string
+		color Color
+		hsl   [3]float64
+	}{
+		{name: "red", color: HexColor(0xFF0000), hsl: [3]float64{0, 1, 0.5}},
+		{name: "green", color: HexColor(0x00FF00), hsl: [3]float64{120, 1, 0.5}},
+		{name: "blue", color: HexColor(0x0000FF), hsl: [3]float64{240, 1, 0.5}},
+		{name: "magenta", color: HexColor(0xFF00FF), hsl: [3]float64{300, 1, 0.5}},
+		{name: "gray", color: HexColor(0x808080), hsl: [3]float64{0, 0, 128.0 / 255}},
+		{name: "dark teal", color: HexColor(0x336666), hsl: [3]float64{180, 1.0 / 3, 0.3}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			h, s, l := test.color.HSL()
+			assert.InDelta(t, test.hsl[0], h, 0.01)
+			assert.InDelta(t, test.hsl[1], s, 0.01)
+			assert.InDelta(t, test.hsl[2], l, 0.01)
+			assert.Equal(t, test.color, HSLColor(h, s, l))
+		})
+	}
+	// Hues wrap
+	assert.Equal(t, HexColor(0xFF0000), HSLColor(360, 1, 0.5))
+	assert.Equal(t, HexColor(0xFF00FF), HSLColor(-60, 1, 0.5))
+}
+
+func TestOKLCH(t *testing.T) {
+	l, c, h := HexColor(0xFFFFFF).OKLCH()
+	assert.InDelta(t, 1, l, 0.001)
+	assert.InDelta(t, 0, c, 0.001)
+	assert.Equal(t, 0.0, h)
+
+	// Reference values from https://oklch.com
+	l, c, h = HexColor(0xFF0000).OKLCH()
+	assert.InDelta(t, 0.628, l, 0.001)
+	assert.InDelta(t, 0.258, c, 0.001)
+	assert.InDelta(t, 29.23, h, 0.01)
+
+	for _, color := range []Color{HexColor(0x336699), HexColor(0xFACADE), IndexColor(208)} {
+		r, g, b := color.RGB()
+		assert.Equal(t, RGBColor(r, g, b), OKLCHColor(color.OKLCH()))
+	}
+	// Colors out of gamut are clipped
+	assert.Equal(t, HexColor(0xFFFFFF), OKLCHColor(1.2, 0, 0))
+}
+
+func TestContrast(t *testing.T) {
+	black := HexColor(0x000000)
+	white := HexColor(0xFFFFFF)
+	assert.InDelta(t, 0, black.Luminance(), 0.0001)
+	assert.InDelta(t, 1, white.Luminance(), 0.0001)
+	assert.InDelta(t, 21, black.Contrast(white), 0.0001)
+	assert.InDelta(t, 21, white.Contrast(black), 0.0001)
+	assert.InDelta(t, 1, white.Contrast(white), 0.0001)
+	assert.InDelta(t, 4.54, HexColor(0x767676).Contrast(white), 0.01)
+}
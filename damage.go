@@ -0,0 +1,64 @@
+package vaxis
+
+// FNV-1a constants. We hash cells directly rather than using hash/fnv so that
+// hashing a row doesn't allocate or format any of the fields
+const (
+	fnvOffset uint64 = 14695981039346656037
+	fnvPrime  uint64 = 1099511628211
+)
+
+func hashString(h uint64, s string) uint64 {
+	for i := 0; i < len(s); i += 1 {
+		h ^= uint64(s[i])
+		h *= fnvPrime
+	}
+	// Terminate the string so adjacent fields can't run together
+	h ^= 0xff
+	h *= fnvPrime
+	return h
+}
+
+func hashUint(h uint64, v uint64) uint64 {
+	h ^= v
+	h *= fnvPrime
+	return h
+}
+
+// rowHash hashes the visible content of a row. Cells which are covered by a
+// wide character are skipped, the same as when rendering. The result is never
+// 0, which is reserved for rows with unknown content
+func (vx *Vaxis) rowHash(s *screen, row int) uint64 {
+	h := fnvOffset
+	for col := 0; col < len(s.buf[row]); col += 1 {
+		cell := s.buf[row][col]
+		h = hashUint(h, uint64(col))
+		h = hashString(h, cell.Grapheme)
+		h = hashUint(h, uint64(cell.Width))
+		h = hashUint(h, uint64(cell.Foreground))
+		h = hashUint(h, uint64(cell.Background))
+		h = hashUint(h, uint64(cell.UnderlineColor))
+		h = hashUint(h, uint64(cell.UnderlineStyle))
+		h = hashUint(h, uint64(cell.Attribute))
+		h = hashString(h, cell.Hyperlink)
+		h = hashString(h, cell.HyperlinkParams)
+		if cell.sixel {
+			h = hashUint(h, 1)
+		}
+		col += vx.advance(cell)
+	}
+	if h == 0 {
+		h = 1
+	}
+	return h
+}
+
+// hashRows updates the hashes of every dirty row of the screen
+func (vx *Vaxis) hashRows(s *screen) {
+	for row := range s.buf {
+		if !s.dirty[row] {
+			continue
+		}
+		s.hashes[row] = vx.rowHash(s, row)
+		s.dirty[row] = false
+	}
+}
@@ -0,0 +1,114 @@
+package vaxis
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRowHash(t *testing.T) {
+	vx, _ := newTestVaxis(10, 1)
+	win := vx.Window()
+	win.Println(0, Segment{Text: "hello"})
+	vx.hashRows(vx.screenNext)
+	base := vx.screenNext.hashes[0]
+	assert.NotZero(t, base)
+
+	tests := []struct {
+		name string
+		cell Cell
+	}{
+		{
+			name: "grapheme",
+			cell: Cell{Character: Character{Grapheme: "j", Width: 1}},
+		},
+		{
+			name: "foreground",
+			cell: Cell{
+				Character: Character{Grapheme: "h", Width: 1},
+				Style:     Style{Foreground: IndexColor(1)},
+			},
+		},
+		{
+			name: "attribute",
+			cell: Cell{
+				Character: Character{Grapheme: "h", Width: 1},
+				Style:     Style{Attribute: AttrBold},
+			},
+		},
+		{
+			name: "hyperlink",
+			cell: Cell{
+				Character: Character{Grapheme: "h", Width: 1},
+				Style:     Style{Hyperlink: "https://example.com"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			win.SetCell(0, 0, test.cell)
+			vx.hashRows(vx.screenNext)
+			assert.NotEqual(t, base, vx.screenNext.hashes[0])
+		})
+	}
+
+	// Writing the same content again gives the same hash
+	win.Println(0, Segment{Text: "hello"})
+	vx.hashRows(vx.screenNext)
+	assert.Equal(t, base, vx.screenNext.hashes[0])
+}
+
+func TestRenderSkipsUnchangedRows(t *testing.T) {
+	vx, _ := newTestVaxis(20, 10)
+	renderLines(vx, logLines(0, 10))
+	stats := vx.FrameStats()
+	assert.Equal(t, 10, stats.Rows)
+	assert.Equal(t, 0, stats.SkippedRows)
+	assert.NotZero(t, stats.Bytes)
+
+	// Redrawing the same content dirties every row, but none of them
+	// changed
+	renderLines(vx, logLines(0, 10))
+	stats = vx.FrameStats()
+	assert.Equal(t, 0, stats.Rows)
+	assert.Equal(t, 10, stats.SkippedRows)
+	assert.Zero(t, stats.Bytes)
+
+	// Only the changed row is compared
+	vx.Window().Println(4, Segment{Text: "changed"})
+	vx.Render()
+	stats = vx.FrameStats()
+	assert.Equal(t, 1, stats.Rows)
+	assert.Equal(t, 9, stats.SkippedRows)
+	for col, char := range Characters("changed") {
+		assert.Equal(t, char.Grapheme, vx.screenLast.buf[4][col].Grapheme)
+	}
+
+	// A refresh renders every row
+	vx.Refresh()
+	stats = vx.FrameStats()
+	assert.Equal(t, 10, stats.Rows)
+}
+
+func BenchmarkRender(b *testing.B) {
+	vx, out := newTestVaxis(400, 120)
+	lines := make([]string, 120)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("%-400d", i)
+	}
+	renderLines(vx, lines)
+
+	b.Run("unchanged", func(b *testing.B) {
+		for i := 0; i < b.N; i += 1 {
+			vx.Render()
+			out.Reset()
+		}
+	})
+	b.Run("redrawn", func(b *testing.B) {
+		for i := 0; i < b.N; i += 1 {
+			renderLines(vx, lines)
+			out.Reset()
+		}
+	})
+}
@@ -14,9 +14,15 @@ type (
 	kittyGraphics          struct{}
 	styledUnderlines       struct{}
 	truecolor              struct{}
+	repeatCharacter        struct{}
 	notifyColorChange      struct{}
+	sgrPixels              struct{}
 	textAreaPix            struct{}
 	textAreaChar           struct{}
+	xtversionReport        string
+	secondaryReport        []int
+	tertiaryReport         string
+	backgroundReport       Color
 )
 
 // Resize is delivered whenever a window size change is detected (likely via
@@ -44,13 +50,13 @@ type FocusOut struct{}
 
 // Redraw is a generic event which can be sent to the host application to tell
 // it some update has occurred it may not know about otherwise and it must
-// redraw. These are always issued after a SyncFunc has been called
+// redraw. Redraw events requested with [Vaxis.RequestRender] are coalesced, so
+// that at most one is delivered per frame
 type Redraw struct{}
 
-// SyncFunc is a function which will be called in the main thread. vaxis will
-// call the function and send an empty SyncFunc event to the application to
-// signal that something has been updated (probably the application needs to
-// redraw itself)
+// SyncFunc is a function which will be called in the main thread. When using
+// [Run], the event loop calls the function and requests a render. Otherwise,
+// the application receives the SyncFunc as an event and must call it
 type SyncFunc func()
 
 // QuitEvent is sent when the application is closing. It is emitted when the
@@ -70,7 +76,9 @@ const (
 )
 
 // ColorThemeUpdate is sent when the terminal color scheme has changed. This
-// event is only delivered if supported by the terminal
+// event is only delivered if supported by the terminal. Otherwise, if the
+// terminal reports its background color, a single ColorThemeUpdate estimated
+// from the background is delivered at startup
 type ColorThemeUpdate struct {
 	Mode ColorThemeMode
 }
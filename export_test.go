@@ -0,0 +1,11 @@
+//go:build darwin || freebsd || linux || netbsd || openbsd
+
+package vaxis
+
+// SetStopSelf replaces the function which stops the process when job control
+// is enabled, and returns a function restoring the original
+func SetStopSelf(fn func() error) (restore func()) {
+	orig := stopSelf
+	stopSelf = fn
+	return func() { stopSelf = orig }
+}
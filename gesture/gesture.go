@@ -0,0 +1,231 @@
+// Package gesture recognizes clicks, drags and scrolls from mouse events. A
+// [Recognizer] keeps the state of the mouse, so widgets don't need to count
+// clicks or track drags themselves:
+//
+//	func (m *Model) Update(ev vaxis.Event) {
+//		switch ev := m.gestures.Update(ev).(type) {
+//		case gesture.Click:
+//			if ev.Count == 2 {
+//				m.selectWord(ev.Col, ev.Row)
+//			}
+//		case gesture.DragMove:
+//			m.moveThumb(ev.Row - ev.OriginRow)
+//		case gesture.Scroll:
+//			m.scroll(ev.DeltaY)
+//		}
+//	}
+package gesture
+
+import (
+	"time"
+
+	"git.sr.ht/~rockorager/vaxis"
+)
+
+// DefaultMultiClickInterval is the longest time between the clicks of a
+// double or triple click
+var DefaultMultiClickInterval = 500 * time.Millisecond
+
+// Click is a press and release of a button without dragging. Modifiers are
+// those held when the button was pressed
+type Click struct {
+	Button vaxis.MouseButton
+	Col    int
+	Row    int
+	// Count is the number of clicks in a row at the same position: 2 for a
+	// double click, 3 for a triple click
+	Count     int
+	Modifiers vaxis.ModifierMask
+}
+
+// DragStart is delivered when the pointer moves while a button is pressed.
+// Modifiers are those held when the button was pressed
+type DragStart struct {
+	Button vaxis.MouseButton
+	// Col and Row are where the button was pressed
+	Col       int
+	Row       int
+	Modifiers vaxis.ModifierMask
+}
+
+// DragMove is delivered for each movement of the pointer after a drag has
+// started. Modifiers are those held when the button was pressed
+type DragMove struct {
+	Button vaxis.MouseButton
+	// Col and Row are the position of the pointer
+	Col int
+	Row int
+	// OriginCol and OriginRow are where the drag started
+	OriginCol int
+	OriginRow int
+	Modifiers vaxis.ModifierMask
+}
+
+// DragEnd is delivered when the button of a drag is released. Modifiers are
+// those held when the button was pressed
+type DragEnd struct {
+	Button vaxis.MouseButton
+	// Col and Row are where the button was released
+	Col int
+	Row int
+	// OriginCol and OriginRow are where the drag started
+	OriginCol int
+	OriginRow int
+	Modifiers vaxis.ModifierMask
+}
+
+// Scroll is delivered for each step of the mouse wheel. DeltaY is negative
+// when scrolling up and positive when scrolling down. DeltaX is negative when
+// scrolling left and positive when scrolling right
+type Scroll struct {
+	Col       int
+	Row       int
+	DeltaX    int
+	DeltaY    int
+	Modifiers vaxis.ModifierMask
+}
+
+// Recognizer turns mouse events into gestures. The zero value is ready to use
+type Recognizer struct {
+	// MultiClickInterval is the longest time between the clicks of a double
+	// or triple click. Zero uses DefaultMultiClickInterval
+	MultiClickInterval time.Duration
+
+	// press is the press of the button being held, if any
+	press    *vaxis.Mouse
+	dragging bool
+	// last is the last click, to count multiple clicks
+	last     Click
+	lastTime time.Time
+	// now returns the current time, and is replaced in tests
+	now func() time.Time
+}
+
+// Update handles an event, and returns the gesture it completes, if any.
+// Events other than [vaxis.Mouse] are ignored, and nil is returned
+func (r *Recognizer) Update(ev vaxis.Event) vaxis.Event {
+	mouse, ok := ev.(vaxis.Mouse)
+	if !ok {
+		return nil
+	}
+	switch mouse.Button {
+	case vaxis.MouseWheelUp:
+		return r.scroll(mouse, 0, -1)
+	case vaxis.MouseWheelDown:
+		return r.scroll(mouse, 0, 1)
+	case vaxis.MouseWheelLeft:
+		return r.scroll(mouse, -1, 0)
+	case vaxis.MouseWheelRight:
+		return r.scroll(mouse, 1, 0)
+	}
+	switch mouse.EventType {
+	case vaxis.EventPress:
+		// A button can't be pressed again before it is released, so a
+		// press while another is held means we missed its release.
+		// Starting over keeps a lost release from sticking
+		r.press = &mouse
+		r.dragging = false
+	case vaxis.EventMotion:
+		return r.motion(mouse)
+	case vaxis.EventRelease:
+		return r.release(mouse)
+	}
+	return nil
+}
+
+func (r *Recognizer) scroll(mouse vaxis.Mouse, dx int, dy int) vaxis.Event {
+	if mouse.EventType != vaxis.EventPress {
+		return nil
+	}
+	return Scroll{
+		Col:       mouse.Col,
+		Row:       mouse.Row,
+		DeltaX:    dx,
+		DeltaY:    dy,
+		Modifiers: mouse.Modifiers,
+	}
+}
+
+func (r *Recognizer) motion(mouse vaxis.Mouse) vaxis.Event {
+	press := r.press
+	if press == nil || mouse.Button != press.Button {
+		return nil
+	}
+	if !r.dragging {
+		if mouse.Col == press.Col && mouse.Row == press.Row {
+			return nil
+		}
+		r.dragging = true
+		return DragStart{
+			Button:    press.Button,
+			Col:       press.Col,
+			Row:       press.Row,
+			Modifiers: press.Modifiers,
+		}
+	}
+	return DragMove{
+		Button:    press.Button,
+		Col:       mouse.Col,
+		Row:       mouse.Row,
+		OriginCol: press.Col,
+		OriginRow: press.Row,
+		Modifiers: press.Modifiers,
+	}
+}
+
+func (r *Recognizer) release(mouse vaxis.Mouse) vaxis.Event {
+	press := r.press
+	// Legacy encodings don't report which button was released
+	if press == nil || (mouse.Button != press.Button && mouse.Button != vaxis.MouseNoButton) {
+		return nil
+	}
+	r.press = nil
+	if r.dragging {
+		r.dragging = false
+		return DragEnd{
+			Button:    press.Button,
+			Col:       mouse.Col,
+			Row:       mouse.Row,
+			OriginCol: press.Col,
+			OriginRow: press.Row,
+			Modifiers: press.Modifiers,
+		}
+	}
+	if mouse.Col != press.Col || mouse.Row != press.Row {
+		// Released elsewhere without motion events, which isn't a
+		// click
+		return nil
+	}
+	now := r.clock()
+	click := Click{
+		Button:    press.Button,
+		Col:       press.Col,
+		Row:       press.Row,
+		Count:     1,
+		Modifiers: press.Modifiers,
+	}
+	if r.last.Count > 0 &&
+		r.last.Button == click.Button &&
+		r.last.Col == click.Col &&
+		r.last.Row == click.Row &&
+		now.Sub(r.lastTime) <= r.interval() {
+		click.Count = r.last.Count + 1
+	}
+	r.last = click
+	r.lastTime = now
+	return click
+}
+
+func (r *Recognizer) clock() time.Time {
+	if r.now != nil {
+		return r.now()
+	}
+	return time.Now()
+}
+
+func (r *Recognizer) interval() time.Duration {
+	if r.MultiClickInterval == 0 {
+		return DefaultMultiClickInterval
+	}
+	return r.MultiClickInterval
+}
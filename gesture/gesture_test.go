@@ -0,0 +1,147 @@
+package gesture
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"git.sr.ht/~rockorager/vaxis"
+)
+
+func press(col int, row int) vaxis.Mouse {
+	return vaxis.Mouse{Button: vaxis.MouseLeftButton, Col: col, Row: row, EventType: vaxis.EventPress}
+}
+
+func release(col int, row int) vaxis.Mouse {
+	return vaxis.Mouse{Button: vaxis.MouseLeftButton, Col: col, Row: row, EventType: vaxis.EventRelease}
+}
+
+func motion(col int, row int) vaxis.Mouse {
+	return vaxis.Mouse{Button: vaxis.MouseLeftButton, Col: col, Row: row, EventType: vaxis.EventMotion}
+}
+
+// recognizer returns a Recognizer with a fake clock, and a function to advance
+// the clock
+func recognizer() (*Recognizer, func(time.Duration)) {
+	now := time.Unix(0, 0)
+	r := &Recognizer{now: func() time.Time { return now }}
+	return r, func(d time.Duration) { now = now.Add(d) }
+}
+
+func TestClick(t *testing.T) {
+	r, advance := recognizer()
+	assert.Nil(t, r.Update(press(1, 2)))
+	assert.Equal(t, Click{Button: vaxis.MouseLeftButton, Col: 1, Row: 2, Count: 1}, r.Update(release(1, 2)))
+
+	advance(100 * time.Millisecond)
+	r.Update(press(1, 2))
+	assert.Equal(t, 2, r.Update(release(1, 2)).(Click).Count)
+	advance(100 * time.Millisecond)
+	r.Update(press(1, 2))
+	assert.Equal(t, 3, r.Update(release(1, 2)).(Click).Count)
+
+	// Too slow
+	advance(time.Second)
+	r.Update(press(1, 2))
+	assert.Equal(t, 1, r.Update(release(1, 2)).(Click).Count)
+
+	// Elsewhere
+	r.Update(press(2, 2))
+	assert.Equal(t, 1, r.Update(release(2, 2)).(Click).Count)
+
+	// A different button
+	r.Update(vaxis.Mouse{Button: vaxis.MouseRightButton, Col: 2, Row: 2})
+	click := r.Update(vaxis.Mouse{Button: vaxis.MouseRightButton, Col: 2, Row: 2, EventType: vaxis.EventRelease})
+	assert.Equal(t, Click{Button: vaxis.MouseRightButton, Col: 2, Row: 2, Count: 1}, click)
+
+	// A custom interval
+	r.MultiClickInterval = 2 * time.Second
+	advance(time.Second)
+	r.Update(vaxis.Mouse{Button: vaxis.MouseRightButton, Col: 2, Row: 2})
+	click = r.Update(vaxis.Mouse{Button: vaxis.MouseRightButton, Col: 2, Row: 2, EventType: vaxis.EventRelease})
+	assert.Equal(t, 2, click.(Click).Count)
+
+	// Legacy encodings release MouseNoButton, and modifiers are from the
+	// press
+	r.Update(vaxis.Mouse{Button: vaxis.MouseMiddleButton, Modifiers: vaxis.ModCtrl})
+	click = r.Update(vaxis.Mouse{Button: vaxis.MouseNoButton, EventType: vaxis.EventRelease})
+	assert.Equal(t, Click{Button: vaxis.MouseMiddleButton, Count: 1, Modifiers: vaxis.ModCtrl}, click)
+
+	// Releases without a press and other events are ignored
+	assert.Nil(t, r.Update(release(0, 0)))
+	assert.Nil(t, r.Update(vaxis.Key{Keycode: 'a'}))
+	assert.Nil(t, r.Update(vaxis.Mouse{Button: vaxis.MouseNoButton, EventType: vaxis.EventMotion}))
+}
+
+func TestDrag(t *testing.T) {
+	r, _ := recognizer()
+	r.Update(vaxis.Mouse{Button: vaxis.MouseLeftButton, Col: 5, Row: 5, Modifiers: vaxis.ModShift})
+	// Motion within the cell doesn't start a drag
+	assert.Nil(t, r.Update(motion(5, 5)))
+	assert.Equal(t, DragStart{
+		Button:    vaxis.MouseLeftButton,
+		Col:       5,
+		Row:       5,
+		Modifiers: vaxis.ModShift,
+	}, r.Update(motion(6, 5)))
+	assert.Equal(t, DragMove{
+		Button:    vaxis.MouseLeftButton,
+		Col:       7,
+		Row:       6,
+		OriginCol: 5,
+		OriginRow: 5,
+		Modifiers: vaxis.ModShift,
+	}, r.Update(vaxis.Mouse{Button: vaxis.MouseLeftButton, Col: 7, Row: 6, EventType: vaxis.EventMotion, Modifiers: vaxis.ModAlt}))
+	// Motion of another button is ignored
+	assert.Nil(t, r.Update(vaxis.Mouse{Button: vaxis.MouseRightButton, Col: 8, Row: 6, EventType: vaxis.EventMotion}))
+	assert.Equal(t, DragEnd{
+		Button:    vaxis.MouseLeftButton,
+		Col:       5,
+		Row:       5,
+		OriginCol: 5,
+		OriginRow: 5,
+		Modifiers: vaxis.ModShift,
+	}, r.Update(release(5, 5)))
+
+	// A drag isn't a click
+	r.Update(press(5, 5))
+	assert.Equal(t, 1, r.Update(release(5, 5)).(Click).Count)
+
+	// Neither is a release elsewhere without motion
+	r.Update(press(1, 1))
+	assert.Nil(t, r.Update(release(2, 1)))
+
+	// A lost release doesn't leave the press stuck: the next press starts
+	// over
+	r.Update(press(1, 1))
+	r.Update(motion(3, 1))
+	r.Update(vaxis.Mouse{Button: vaxis.MouseRightButton, Col: 4, Row: 4})
+	click := r.Update(vaxis.Mouse{Button: vaxis.MouseRightButton, Col: 4, Row: 4, EventType: vaxis.EventRelease})
+	assert.Equal(t, Click{Button: vaxis.MouseRightButton, Col: 4, Row: 4, Count: 1}, click)
+	r.Update(press(6, 6))
+	assert.Equal(t, DragStart{Button: vaxis.MouseLeftButton, Col: 6, Row: 6}, r.Update(motion(7, 6)))
+}
+
+func TestScroll(t *testing.T) {
+	r, _ := recognizer()
+	tests := []struct {
+		button   vaxis.MouseButton
+		expected Scroll
+	}{
+		{button: vaxis.MouseWheelUp, expected: Scroll{Col: 3, Row: 4, DeltaY: -1, Modifiers: vaxis.ModCtrl}},
+		{button: vaxis.MouseWheelDown, expected: Scroll{Col: 3, Row: 4, DeltaY: 1, Modifiers: vaxis.ModCtrl}},
+		{button: vaxis.MouseWheelLeft, expected: Scroll{Col: 3, Row: 4, DeltaX: -1, Modifiers: vaxis.ModCtrl}},
+		{button: vaxis.MouseWheelRight, expected: Scroll{Col: 3, Row: 4, DeltaX: 1, Modifiers: vaxis.ModCtrl}},
+	}
+	for _, test := range tests {
+		ev := r.Update(vaxis.Mouse{Button: test.button, Col: 3, Row: 4, Modifiers: vaxis.ModCtrl})
+		assert.Equal(t, test.expected, ev)
+	}
+
+	// Scrolling during a drag doesn't end it
+	r.Update(press(0, 0))
+	r.Update(motion(1, 0))
+	assert.Equal(t, Scroll{DeltaY: 1}, r.Update(vaxis.Mouse{Button: vaxis.MouseWheelDown}))
+	assert.IsType(t, DragMove{}, r.Update(motion(2, 0)))
+}
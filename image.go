@@ -166,7 +166,7 @@ func (k *KittyImage) Resize(w int, h int) {
 			}
 			fmt.Fprintf(k.buf, "\x1B_Gf=100,i=%d,m=%d;%s\x1B\\", k.id, m, string(b[:n]))
 		}
-		k.vx.PostEvent(Redraw{})
+		k.vx.RequestRender()
 	}()
 }
 
@@ -256,12 +256,14 @@ func (s *Sixel) Resize(w int, h int) {
 		}
 		// Re-encode the image
 		s.buf.Reset()
-		err := sixel.NewEncoder(s.buf).Encode(img)
+		enc := sixel.NewEncoder(s.buf)
+		enc.Colors = s.vx.quirks.sixelColors
+		err := enc.Encode(img)
 		if err != nil {
 			log.Error("couldn't encode sixel: %v", err)
 			return
 		}
-		s.vx.PostEvent(Redraw{})
+		s.vx.RequestRender()
 	}()
 }
 
@@ -0,0 +1,219 @@
+package vaxis
+
+import (
+	"strings"
+	"sync/atomic"
+
+	"git.sr.ht/~rockorager/vaxis/log"
+)
+
+// inlineState tracks the region of the primary screen used when rendering
+// inline. All cursor movement within the region is relative to the physical
+// cursor, which we track as we write to the terminal. This lets us render
+// without knowing (or trusting) the absolute position of the region
+type inlineState struct {
+	// height is the requested height of the region. Zero means the full
+	// height of the terminal
+	height int
+	// grow is true when height is a maximum, and the region grows to fit
+	// the content which has been drawn
+	grow bool
+	// reserved is the number of rows currently reserved for the region
+	reserved int
+	// anchor is the absolute row of the top of the region. This is only
+	// used to translate mouse events, and is accessed atomically
+	anchor int32
+	// row and col are the position of the physical cursor, relative to the
+	// top left of the region. col may be equal to the width of the screen
+	// if a wrap is pending
+	row int
+	col int
+}
+
+// inlineRows returns the number of rows the screen buffers should have for a
+// terminal with the given number of rows
+func (vx *Vaxis) inlineRows(rows int) int {
+	if vx.inline.height <= 0 || vx.inline.height > rows {
+		return rows
+	}
+	return vx.inline.height
+}
+
+// enterInline anchors the inline region at the current cursor position and
+// reserves the rows it needs, scrolling the terminal if necessary
+func (vx *Vaxis) enterInline() {
+	row, col := vx.CursorPosition()
+	log.Debug("[inline] anchor at row=%d col=%d", row, col)
+	vx.refresh = true
+	vx.inline.row = 0
+	vx.inline.col = 0
+	vx.inline.reserved = 1
+	_, _ = vx.tw.WriteString(decrst(cursorVisibility))
+	if col > 0 {
+		// Start the region on a fresh line so we don't draw over a
+		// prompt
+		_, _ = vx.tw.WriteString("\r\n")
+		row += 1
+	}
+	if row < 0 || row >= vx.winSize.Rows {
+		// We couldn't tell where we are, assume we are at the bottom
+		row = vx.winSize.Rows - 1
+	}
+	vx.setInlineAnchor(row)
+	if !vx.inline.grow {
+		vx.reserveInline(vx.inlineRows(vx.winSize.Rows))
+	}
+	_, _ = vx.tw.WriteString("\r")
+	_, _ = vx.tw.Flush()
+}
+
+// exitInline leaves the last frame in place and moves the cursor to the line
+// below the region, making it visible
+func (vx *Vaxis) exitInline() {
+	vx.HideCursor()
+	_, _ = vx.tw.WriteString(vx.moveCursor(vx.inline.reserved-1, 0))
+	_, _ = vx.tw.WriteString("\r\n")
+	_, _ = vx.tw.WriteString(decset(cursorVisibility))
+	_, _ = vx.tw.Flush()
+	vx.inline.row = 0
+	vx.inline.col = 0
+}
+
+// resizeInline redraws the region after the terminal has been resized. The
+// terminal may have reflowed our previous frame, so we erase everything from
+// the top of the region down and reserve the region again
+func (vx *Vaxis) resizeInline() {
+	_, _ = vx.tw.WriteString(vx.moveCursor(0, 0))
+	_, _ = vx.tw.WriteString(eraseBelow)
+	_, _ = vx.tw.Flush()
+	reserved := vx.inline.reserved
+	vx.inline.reserved = 1
+	row, _ := vx.CursorPosition()
+	if row < 0 || row >= vx.winSize.Rows {
+		row = vx.winSize.Rows - 1
+	}
+	vx.setInlineAnchor(row)
+	switch vx.inline.grow {
+	case true:
+		vx.reserveInline(reserved)
+	case false:
+		vx.reserveInline(vx.inlineRows(vx.winSize.Rows))
+	}
+	_, _ = vx.tw.Flush()
+}
+
+// reserveInline grows the region to the given number of rows. Rows are
+// reserved by writing newlines from the last row of the region, which will
+// scroll the terminal if the region extends past the bottom of the screen
+func (vx *Vaxis) reserveInline(rows int) {
+	if rows > vx.winSize.Rows {
+		rows = vx.winSize.Rows
+	}
+	if rows <= vx.inline.reserved {
+		return
+	}
+	_, _ = vx.tw.WriteString(vx.moveCursor(vx.inline.reserved-1, 0))
+	_, _ = vx.tw.WriteString(strings.Repeat("\n", rows-vx.inline.reserved))
+	vx.inline.row = rows - 1
+	vx.inline.reserved = rows
+	anchor := int(atomic.LoadInt32(&vx.inline.anchor))
+	if anchor+rows > vx.winSize.Rows {
+		vx.setInlineAnchor(vx.winSize.Rows - rows)
+	}
+}
+
+func (vx *Vaxis) setInlineAnchor(row int) {
+	atomic.StoreInt32(&vx.inline.anchor, int32(row))
+}
+
+// inlineAnchor returns the absolute row of the top of the inline region, or 0
+// if we aren't rendering inline
+func (vx *Vaxis) inlineAnchor() int {
+	if vx.inline == nil {
+		return 0
+	}
+	return int(atomic.LoadInt32(&vx.inline.anchor))
+}
+
+// inlineMouse makes the position of a mouse event relative to the inline
+// region. Events above the region are dropped, except releases, which are
+// moved to the top row so that drags leaving the region still end. false is
+// returned if the event is dropped
+func (vx *Vaxis) inlineMouse(mouse Mouse) (Mouse, bool) {
+	anchor := vx.inlineAnchor()
+	if anchor == 0 {
+		return mouse, true
+	}
+	mouse.Row -= anchor
+	if atomicLoad(&vx.mousePixels) {
+		mouse.YPixel -= anchor * int(atomic.LoadInt32(&vx.cellHeight))
+	}
+	if mouse.Row >= 0 && mouse.YPixel >= 0 {
+		return mouse, true
+	}
+	if mouse.EventType != EventRelease {
+		return mouse, false
+	}
+	mouse.Row = 0
+	mouse.YPixel = 0
+	return mouse, true
+}
+
+// inlineContentRows returns the number of rows of the next screen which have
+// content, with a minimum of one
+func (vx *Vaxis) inlineContentRows() int {
+	for row := len(vx.screenNext.buf) - 1; row > 0; row -= 1 {
+		for _, cell := range vx.screenNext.buf[row] {
+			switch {
+			case cell.Style != Style{}:
+				return row + 1
+			case cell.Grapheme != "" && cell.Grapheme != " ":
+				return row + 1
+			}
+		}
+	}
+	return 1
+}
+
+// moveCursor returns the sequence to move the cursor to row, col of the screen.
+// When rendering inline the movement is relative to the tracked position of
+// the physical cursor, otherwise it is an absolute position
+func (vx *Vaxis) moveCursor(row int, col int) string {
+	if vx.inline == nil {
+		return tparm(cup, row+1, col+1)
+	}
+	// Always start with a carriage return. This clears any pending wrap
+	// and gives us a known column
+	bldr := strings.Builder{}
+	bldr.WriteString("\r")
+	switch {
+	case row < vx.inline.row:
+		bldr.WriteString(tparm(cuu, vx.inline.row-row))
+	case row > vx.inline.row:
+		bldr.WriteString(tparm(cud, row-vx.inline.row))
+	}
+	if col > 0 {
+		bldr.WriteString(tparm(cuf, col))
+	}
+	vx.inline.row = row
+	vx.inline.col = col
+	return bldr.String()
+}
+
+// advanceInline updates the tracked physical cursor after printing a
+// character of the given width
+func (vx *Vaxis) advanceInline(width int) {
+	if vx.inline == nil {
+		return
+	}
+	if width < 1 {
+		width = 1
+	}
+	if vx.inline.col+width > vx.screenNext.cols {
+		// Autowrap to the next line
+		vx.inline.row += 1
+		vx.inline.col = width
+		return
+	}
+	vx.inline.col += width
+}
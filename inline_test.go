@@ -0,0 +1,96 @@
+package vaxis_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"git.sr.ht/~rockorager/vaxis"
+	"git.sr.ht/~rockorager/vaxis/vaxistest"
+)
+
+func TestInline(t *testing.T) {
+	tt, err := vaxistest.New(20, 6, vaxis.Options{
+		Inline:       true,
+		InlineHeight: 3,
+	})
+	require.NoError(t, err)
+	defer tt.Close()
+
+	win := tt.Vx.Window()
+	w, h := win.Size()
+	assert.Equal(t, 20, w)
+	assert.Equal(t, 3, h)
+
+	win.Println(0, vaxis.Segment{Text: "one"})
+	win.Println(2, vaxis.Segment{Text: "three"})
+	require.NoError(t, tt.Render())
+	assert.Equal(t, "one                 ", tt.Row(0))
+	assert.Equal(t, "three               ", tt.Row(2))
+
+	// Leave the frame in place, and print a shell prompt below it
+	require.NoError(t, tt.Vx.Suspend())
+	require.NoError(t, tt.WriteString("$ ls\r\nfoo\r\n$ "))
+	assert.Equal(t, "three               ", tt.Row(2))
+	assert.Equal(t, "$ ls                ", tt.Row(3))
+
+	// Resuming anchors below the prompt, scrolling the terminal to make
+	// room for the region
+	require.NoError(t, tt.Vx.Resume())
+	win = tt.Vx.Window()
+	win.Clear()
+	win.Println(0, vaxis.Segment{Text: "new"})
+	require.NoError(t, tt.Render())
+	assert.Equal(t, "$ ls                ", tt.Row(0))
+	assert.Equal(t, "foo                 ", tt.Row(1))
+	assert.Equal(t, "$                   ", tt.Row(2))
+	assert.Equal(t, "new                 ", tt.Row(3))
+
+	// Mouse events are relative to the region
+	for tt.NextEvent(10*time.Millisecond) != nil {
+	}
+	tt.SendMouse(vaxis.Mouse{
+		Button:    vaxis.MouseLeftButton,
+		Col:       1,
+		Row:       4,
+		EventType: vaxis.EventPress,
+	})
+	ev := tt.NextEvent(time.Second)
+	mouse, ok := ev.(vaxis.Mouse)
+	require.True(t, ok, "expected a mouse event, got %#v", ev)
+	assert.Equal(t, 1, mouse.Row)
+}
+
+func TestInlineGrow(t *testing.T) {
+	tt, err := vaxistest.New(20, 6, vaxis.Options{
+		Inline:       true,
+		InlineHeight: 4,
+		InlineGrow:   true,
+	})
+	require.NoError(t, err)
+	defer tt.Close()
+
+	win := tt.Vx.Window()
+	_, h := win.Size()
+	assert.Equal(t, 4, h)
+	win.Println(0, vaxis.Segment{Text: "a"})
+	win.Println(1, vaxis.Segment{Text: "b"})
+	require.NoError(t, tt.Render())
+
+	// The region only grew to two rows, so the cursor is left on the third
+	require.NoError(t, tt.Vx.Suspend())
+	require.NoError(t, tt.WriteString("$"))
+	assert.Equal(t, "a\nb\n$", trimRows(tt.String()))
+	require.NoError(t, tt.Vx.Resume())
+}
+
+func trimRows(s string) string {
+	rows := strings.Split(s, "\n")
+	for i := range rows {
+		rows[i] = strings.TrimRight(rows[i], " ")
+	}
+	return strings.TrimRight(strings.Join(rows, "\n"), "\n")
+}
@@ -0,0 +1,166 @@
+//go:build darwin || freebsd || linux || netbsd || openbsd
+
+package vaxis_test
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"git.sr.ht/~rockorager/vaxis"
+	"git.sr.ht/~rockorager/vaxis/vaxistest"
+)
+
+func TestRunExternal(t *testing.T) {
+	tt, err := vaxistest.New(20, 4, vaxis.Options{})
+	require.NoError(t, err)
+	defer tt.Close()
+	// Initial resize
+	tt.NextEvent(time.Second)
+
+	// The command is connected to the terminal
+	out := &bytes.Buffer{}
+	cmd := exec.Command("sh", "-c", "test -t 0 && printf external")
+	cmd.Stdout = out
+	require.NoError(t, tt.Vx.RunExternal(cmd))
+	assert.Equal(t, "external", out.String())
+
+	// A Resize is delivered after resuming, even though the size didn't
+	// change
+	require.NoError(t, tt.Render())
+	var resize vaxis.Resize
+	for {
+		ev := tt.NextEvent(time.Second)
+		require.NotNil(t, ev, "expected a resize event")
+		if r, ok := ev.(vaxis.Resize); ok {
+			resize = r
+			break
+		}
+	}
+	assert.Equal(t, 20, resize.Cols)
+	assert.Equal(t, 4, resize.Rows)
+
+	// Errors from the command are returned, and we're still resumed
+	assert.Error(t, tt.Vx.RunExternal(exec.Command("false")))
+	tt.Vx.Window().Println(0, vaxis.Segment{Text: "back"})
+	require.NoError(t, tt.Render())
+	assert.Equal(t, "back                ", tt.Row(0))
+}
+
+func TestRunExternalInput(t *testing.T) {
+	tt, err := vaxistest.New(20, 4, vaxis.Options{})
+	require.NoError(t, err)
+	defer tt.Close()
+	tt.NextEvent(time.Second)
+
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	defer r.Close()
+	cmd := exec.Command("sh", "-c", `printf ready; read line; printf "%s" "$line"`)
+	cmd.Stdout = w
+	chErr := make(chan error, 1)
+	go func() {
+		chErr <- tt.Vx.RunExternal(cmd)
+		w.Close()
+	}()
+
+	// Type a line once the command is waiting for it
+	ready := make([]byte, len("ready"))
+	_, err = io.ReadFull(r, ready)
+	require.NoError(t, err)
+	for _, key := range []vaxis.Key{
+		{Keycode: 'h', Text: "h"},
+		{Keycode: 'i', Text: "i"},
+		{Keycode: vaxis.KeyEnter},
+	} {
+		tt.SendKey(key)
+	}
+	select {
+	case err := <-chErr:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		_ = cmd.Process.Kill()
+		t.Fatal("the command didn't receive the line")
+	}
+	line, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, "hi", string(line))
+
+	// The command read the line, so Vaxis must not have seen the keys
+	for {
+		ev := tt.NextEvent(100 * time.Millisecond)
+		if ev == nil {
+			break
+		}
+		_, isKey := ev.(vaxis.Key)
+		assert.False(t, isKey, "unexpected key: %#v", ev)
+	}
+}
+
+func TestCtrlZWithoutJobControl(t *testing.T) {
+	tt, err := vaxistest.New(20, 4, vaxis.Options{})
+	require.NoError(t, err)
+	defer tt.Close()
+	tt.NextEvent(time.Second)
+
+	tt.SendKey(vaxis.Key{Keycode: 'z', Modifiers: vaxis.ModCtrl})
+	ev := tt.NextEvent(time.Second)
+	require.IsType(t, vaxis.Key{}, ev)
+	assert.True(t, ev.(vaxis.Key).Matches('z', vaxis.ModCtrl))
+}
+
+func TestCtrlZWithJobControl(t *testing.T) {
+	stopped := make(chan struct{})
+	cont := make(chan struct{})
+	defer vaxis.SetStopSelf(func() error {
+		close(stopped)
+		<-cont
+		return nil
+	})()
+	tt, err := vaxistest.New(20, 4, vaxis.Options{JobControl: true})
+	require.NoError(t, err)
+	defer tt.Close()
+	tt.NextEvent(time.Second)
+
+	tt.Vx.Window().Println(0, vaxis.Segment{Text: "before"})
+	require.NoError(t, tt.Render())
+	tt.SendKey(vaxis.Key{Keycode: 'z', Modifiers: vaxis.ModCtrl})
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("the process wasn't stopped")
+	}
+
+	// We left the alternate screen, and renders while stopped don't draw
+	// over the shell
+	require.NoError(t, tt.Sync())
+	assert.Equal(t, "                    ", tt.Row(0))
+	tt.Vx.Window().Println(0, vaxis.Segment{Text: "stopped"})
+	require.NoError(t, tt.Render())
+	assert.Equal(t, "                    ", tt.Row(0))
+
+	// Once continued, a Resize is delivered and we draw again. The key
+	// which stopped us is never delivered
+	close(cont)
+	deadline := time.Now().Add(time.Second)
+	var resized bool
+	for !resized {
+		require.True(t, time.Now().Before(deadline), "expected a resize event")
+		require.NoError(t, tt.Render())
+		ev := tt.NextEvent(100 * time.Millisecond)
+		switch ev.(type) {
+		case vaxis.Resize:
+			resized = true
+		case vaxis.Key:
+			t.Fatalf("unexpected key: %#v", ev)
+		}
+	}
+	require.NoError(t, tt.Render())
+	assert.Equal(t, "stopped             ", tt.Row(0))
+}
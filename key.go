@@ -125,22 +125,8 @@ func (k Key) MatchString(tgt string) bool {
 
 	var mask ModifierMask
 	for _, m := range mods {
-		switch strings.ToLower(m) {
-		case "shift":
-			mask |= ModShift
-		case "alt":
-			mask |= ModAlt
-		case "ctrl":
-			mask |= ModCtrl
-		case "super":
-			mask |= ModSuper
-		case "meta":
-			mask |= ModMeta
-		case "caps":
-			mask |= ModCapsLock
-		case "num":
-			mask |= ModNumLock
-		}
+		mod, _ := parseModifier(m)
+		mask |= mod
 	}
 	if r, n := utf8.DecodeRuneInString(key); n == len(key) {
 		// fast path if the 'key' is unicode
@@ -162,6 +148,63 @@ func (k Key) MatchString(tgt string) bool {
 	return false
 }
 
+// ParseKey parses a key in the syntax of [Key.String], such as "Ctrl+p",
+// "Shift+Alt+Up" or "Ctrl++". Modifiers and key names are case insensitive.
+// The returned Key has the Keycode and Modifiers set, and can be matched
+// against key events with [Key.Matches]
+func ParseKey(s string) (Key, error) {
+	if s == "" {
+		return Key{}, fmt.Errorf("vaxis: empty key")
+	}
+	key := Key{}
+	name := s
+	// The key itself may be '+', so the last character is never a
+	// separator
+	if i := strings.LastIndex(s[:len(s)-1], "+"); i >= 0 {
+		for _, m := range strings.Split(s[:i], "+") {
+			mod, ok := parseModifier(m)
+			if !ok {
+				return Key{}, fmt.Errorf("vaxis: invalid modifier %q in key %q", m, s)
+			}
+			key.Modifiers |= mod
+		}
+		name = s[i+1:]
+	}
+	if r, n := utf8.DecodeRuneInString(name); n == len(name) {
+		key.Keycode = r
+		return key, nil
+	}
+	for _, kn := range keyNames {
+		if strings.EqualFold(kn.name, name) {
+			key.Keycode = kn.key
+			return key, nil
+		}
+	}
+	return Key{}, fmt.Errorf("vaxis: invalid key: %q", s)
+}
+
+func parseModifier(name string) (ModifierMask, bool) {
+	switch strings.ToLower(name) {
+	case "shift":
+		return ModShift, true
+	case "alt":
+		return ModAlt, true
+	case "ctrl":
+		return ModCtrl, true
+	case "super":
+		return ModSuper, true
+	case "hyper":
+		return ModHyper, true
+	case "meta":
+		return ModMeta, true
+	case "caps":
+		return ModCapsLock, true
+	case "num":
+		return ModNumLock, true
+	}
+	return 0, false
+}
+
 // ModifierMask is a bitmask for which modifier keys were held when a key was
 // pressed
 type ModifierMask int
@@ -303,7 +346,7 @@ func decodeKey(seq ansi.Sequence) Key {
 			switch {
 			case rune(seq) == 0x00:
 				key.Keycode = '@'
-			case rune(seq) < 0x1A:
+			case rune(seq) <= 0x1A:
 				// normalize these to lowercase runes
 				key.Keycode = rune(seq) + 0x60
 			case rune(seq) < 0x20:
@@ -342,6 +385,9 @@ func decodeKey(seq ansi.Sequence) Key {
 				{1},
 			}
 		}
+		if seq.Final == '~' && len(seq.Parameters) == 3 && seq.Parameters[0][0] == 27 {
+			return decodeModifyOtherKeys(seq)
+		}
 		for i, pm := range seq.Parameters {
 			switch i {
 			case 0:
@@ -396,6 +442,29 @@ func decodeKey(seq ansi.Sequence) Key {
 				}
 			}
 		}
+		if seq.Final == 'u' && key.ShiftedCode == 0 && key.Modifiers&ModShift != 0 && unicode.IsUpper(key.Keycode) {
+			// xterm's modifyOtherKeys reports the shifted code of
+			// letters. Normalize them the way kitty reports them
+			key.ShiftedCode = key.Keycode
+			key.Keycode = unicode.ToLower(key.Keycode)
+		}
+	}
+	return key
+}
+
+// decodeModifyOtherKeys decodes a key reported by xterm's modifyOtherKeys,
+// CSI 27 ; modifiers ; code ~, which is the same key as CSI code ; modifiers u
+func decodeModifyOtherKeys(seq ansi.CSI) Key {
+	key := decodeKey(ansi.CSI{
+		Final:      'u',
+		Parameters: [][]int{seq.Parameters[2], seq.Parameters[1]},
+	})
+	// Keys without Ctrl or Alt type their text
+	if key.Modifiers&^(ModShift|ModCapsLock|ModNumLock) == 0 && key.Keycode < extended && unicode.IsPrint(key.Keycode) {
+		key.Text = string(key.Keycode)
+		if key.ShiftedCode != 0 {
+			key.Text = string(key.ShiftedCode)
+		}
 	}
 	return key
 }
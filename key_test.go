@@ -241,6 +241,34 @@ func TestKeyMatches(t *testing.T) {
 			matchMods:   ModCapsLock,
 			matchString: "P",
 		},
+		{
+			name:        "modifyOtherKeys: 'ctrl+i'",
+			sequence:    "\x1b[27;5;105~",
+			matchRune:   'i',
+			matchMods:   ModCtrl,
+			matchString: "ctrl+i",
+		},
+		{
+			name:        "modifyOtherKeys: 'ctrl+shift+a'",
+			sequence:    "\x1b[27;6;65~",
+			matchRune:   'a',
+			matchMods:   ModCtrl | ModShift,
+			matchString: "ctrl+shift+a",
+		},
+		{
+			name:        "formatOtherKeys: 'ctrl+shift+a'",
+			sequence:    "\x1b[65;6u",
+			matchRune:   'a',
+			matchMods:   ModCtrl | ModShift,
+			matchString: "ctrl+shift+a",
+		},
+		{
+			name:        "modifyOtherKeys: 'ctrl+enter'",
+			sequence:    "\x1b[27;5;13~",
+			matchRune:   KeyEnter,
+			matchMods:   ModCtrl,
+			matchString: "ctrl+enter",
+		},
 	}
 
 	for _, test := range shouldMatch {
@@ -291,7 +319,7 @@ func TestKeyDecode(t *testing.T) {
 	}{
 		{
 			name:     "legacy: j",
-			sequence: ansi.Print{"j", 1},
+			sequence: ansi.Print{Grapheme: "j", Width: 1},
 			expected: Key{
 				Keycode: 'j',
 				Text:    "j",
@@ -309,7 +337,7 @@ func TestKeyDecode(t *testing.T) {
 		},
 		{
 			name:     "legacy: shift+j",
-			sequence: ansi.Print{"J", 1},
+			sequence: ansi.Print{Grapheme: "J", Width: 1},
 			expected: Key{
 				Keycode:     'j',
 				ShiftedCode: 'J',
@@ -363,6 +391,65 @@ func TestKeyDecode(t *testing.T) {
 				Text:           "ф",
 			},
 		},
+		{
+			name: "modifyOtherKeys: ctrl+i",
+			sequence: ansi.CSI{
+				Final:      '~',
+				Parameters: [][]int{{27}, {5}, {105}},
+			},
+			expected: Key{
+				Keycode:   'i',
+				Modifiers: ModCtrl,
+			},
+		},
+		{
+			name: "modifyOtherKeys: shift+a",
+			sequence: ansi.CSI{
+				Final:      '~',
+				Parameters: [][]int{{27}, {2}, {65}},
+			},
+			expected: Key{
+				Keycode:     'a',
+				ShiftedCode: 'A',
+				Modifiers:   ModShift,
+				Text:        "A",
+			},
+		},
+		{
+			name: "modifyOtherKeys: shift+space",
+			sequence: ansi.CSI{
+				Final:      '~',
+				Parameters: [][]int{{27}, {2}, {32}},
+			},
+			expected: Key{
+				Keycode:   KeySpace,
+				Modifiers: ModShift,
+				Text:      " ",
+			},
+		},
+		{
+			name: "modifyOtherKeys: ctrl+tab",
+			sequence: ansi.CSI{
+				Final:      '~',
+				Parameters: [][]int{{27}, {5}, {9}},
+			},
+			expected: Key{
+				Keycode:   KeyTab,
+				Modifiers: ModCtrl,
+			},
+		},
+		{
+			name: "formatOtherKeys: ctrl+shift+a",
+			sequence: ansi.CSI{
+				Final:      'u',
+				Parameters: [][]int{{65}, {6}},
+			},
+			expected: Key{
+				Keycode:     'a',
+				ShiftedCode: 'A',
+				Modifiers:   ModCtrl | ModShift,
+			},
+		},
 		{
 			name: "kitty: multiple codepoints",
 			sequence: ansi.CSI{
@@ -387,3 +474,36 @@ func TestKeyDecode(t *testing.T) {
 		})
 	}
 }
+
+func TestParseKey(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Key
+	}{
+		{input: "j", expected: Key{Keycode: 'j'}},
+		{input: "+", expected: Key{Keycode: '+'}},
+		{input: "Ctrl+p", expected: Key{Keycode: 'p', Modifiers: ModCtrl}},
+		{input: "ctrl+ALT+Up", expected: Key{Keycode: KeyUp, Modifiers: ModCtrl | ModAlt}},
+		{input: "Ctrl++", expected: Key{Keycode: '+', Modifiers: ModCtrl}},
+		{input: "Hyper+space", expected: Key{Keycode: KeySpace, Modifiers: ModHyper}},
+		{input: "BackSpace", expected: Key{Keycode: KeyBackspace}},
+		{input: "F12", expected: Key{Keycode: KeyF12}},
+		{input: "é", expected: Key{Keycode: 'é'}},
+	}
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			key, err := ParseKey(test.input)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, key)
+			// Parsing round trips with String
+			key, err = ParseKey(key.String())
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, key)
+		})
+	}
+
+	for _, input := range []string{"", "Ctrl+", "Fn+a", "Ctrl+Nope", "a+"} {
+		_, err := ParseKey(input)
+		assert.Error(t, err, input)
+	}
+}
@@ -0,0 +1,131 @@
+package vaxis
+
+import (
+	"context"
+	"io"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// KeyboardFlags are the progressive enhancements of the kitty keyboard
+// protocol. See https://sw.kovidgoyal.net/kitty/keyboard-protocol/
+type KeyboardFlags int
+
+const (
+	// KeyboardDisambiguate reports keys which are ambiguous in legacy
+	// encodings, such as Escape, Alt+key and Ctrl+i, as escape sequences
+	KeyboardDisambiguate KeyboardFlags = 1 << iota
+	// KeyboardReportEvents reports repeat and release events. Enter, Tab
+	// and Backspace only report them with KeyboardAllKeys
+	KeyboardReportEvents
+	// KeyboardAlternateKeys reports the shifted key and the key of the
+	// base layout, which are used by [Key.Matches]
+	KeyboardAlternateKeys
+	// KeyboardAllKeys reports every key as an escape sequence, including
+	// Enter, Tab, Backspace and keys which produce text
+	KeyboardAllKeys
+	// KeyboardAssociatedText reports the text produced by a key. Only used
+	// with KeyboardAllKeys
+	KeyboardAssociatedText
+)
+
+// defaultKeyboardFlags are the flags used when Options.KeyboardFlags is zero
+const defaultKeyboardFlags = KeyboardDisambiguate | KeyboardAlternateKeys | KeyboardAllKeys | KeyboardAssociatedText
+
+// KeyboardFlags returns the keyboard flags Vaxis requested from the
+// terminal: the last flags pushed with [Vaxis.PushKeyboardFlags], or those
+// set by Options. Zero is returned if the terminal doesn't support the kitty
+// keyboard protocol
+func (vx *Vaxis) KeyboardFlags() KeyboardFlags {
+	vx.mu.Lock()
+	defer vx.mu.Unlock()
+	if !vx.caps.kittyKeyboard || len(vx.keyboardFlags) == 0 {
+		return 0
+	}
+	return vx.keyboardFlags[len(vx.keyboardFlags)-1]
+}
+
+// PushKeyboardFlags makes flags the active keyboard flags until
+// [Vaxis.PopKeyboardFlags] is called, for example while a game needs release
+// events. Pushed flags are restored when Vaxis resumes. PushKeyboardFlags does
+// nothing if the terminal doesn't support the kitty keyboard protocol
+func (vx *Vaxis) PushKeyboardFlags(flags KeyboardFlags) {
+	vx.mu.Lock()
+	defer vx.mu.Unlock()
+	if !vx.caps.kittyKeyboard {
+		return
+	}
+	vx.keyboardFlags = append(vx.keyboardFlags, flags)
+	_, _ = io.WriteString(vx.console, tparm(kittyKBPush, flags))
+}
+
+// PopKeyboardFlags restores the keyboard flags active before the last call to
+// [Vaxis.PushKeyboardFlags]. The flags set by Options are never popped
+func (vx *Vaxis) PopKeyboardFlags() {
+	vx.mu.Lock()
+	defer vx.mu.Unlock()
+	if !vx.caps.kittyKeyboard || len(vx.keyboardFlags) < 2 {
+		return
+	}
+	vx.keyboardFlags = vx.keyboardFlags[:len(vx.keyboardFlags)-1]
+	_, _ = io.WriteString(vx.console, tparm(kittyKBPop, 1))
+}
+
+// QueryKeyboardFlags requests the active keyboard flags from the terminal.
+// The terminal may not support all of the flags Vaxis requested. Zero is
+// returned if the terminal doesn't support the kitty keyboard protocol. An
+// error is returned if the context is done before the terminal replies
+func (vx *Vaxis) QueryKeyboardFlags(ctx context.Context) (KeyboardFlags, error) {
+	if !vx.caps.kittyKeyboard {
+		return 0, nil
+	}
+	vx.keyboardQuery.Lock()
+	defer vx.keyboardQuery.Unlock()
+	atomic.StoreInt32(&vx.keyboardQueryPending, 1)
+	defer atomic.StoreInt32(&vx.keyboardQueryPending, 0)
+	_, _ = io.WriteString(vx.console, kittyKBQuery)
+	select {
+	case flags := <-vx.chKeyboardFlags:
+		return flags, nil
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+}
+
+// handleKeyboardFlags delivers the flags to a pending query. Without one,
+// the reply is to the query sent at startup, and is dropped if it arrives
+// after we stopped waiting for it
+func (vx *Vaxis) handleKeyboardFlags(flags KeyboardFlags) {
+	if atomic.LoadInt32(&vx.keyboardQueryPending) == 0 {
+		if atomicLoad(&vx.detecting) {
+			vx.PostEvent(kittyKeyboard{})
+		}
+		return
+	}
+	select {
+	case vx.chKeyboardFlags <- flags:
+	case <-time.After(10 * time.Millisecond):
+	}
+}
+
+// pushKeyboardFlags returns the sequences to push the stack of keyboard flags
+func (vx *Vaxis) pushKeyboardFlags() string {
+	vx.mu.Lock()
+	defer vx.mu.Unlock()
+	b := strings.Builder{}
+	for _, flags := range vx.keyboardFlags {
+		b.WriteString(tparm(kittyKBPush, flags))
+	}
+	return b.String()
+}
+
+// popKeyboardFlags returns the sequence to pop the stack of keyboard flags
+func (vx *Vaxis) popKeyboardFlags() string {
+	vx.mu.Lock()
+	defer vx.mu.Unlock()
+	if len(vx.keyboardFlags) == 0 {
+		return ""
+	}
+	return tparm(kittyKBPop, len(vx.keyboardFlags))
+}
@@ -0,0 +1,103 @@
+package vaxis
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"git.sr.ht/~rockorager/vaxis/ansi"
+)
+
+func TestKeyboardFlags(t *testing.T) {
+	vx, c := newReplyVaxis("")
+	vx.caps.kittyKeyboard = true
+	vx.keyboardFlags = []KeyboardFlags{defaultKeyboardFlags}
+	assert.Equal(t, "\x1b[>29u", vx.pushKeyboardFlags())
+	assert.Equal(t, "\x1b[<1u", vx.popKeyboardFlags())
+
+	vx.PushKeyboardFlags(KeyboardDisambiguate | KeyboardReportEvents | KeyboardAllKeys)
+	assert.Equal(t, "\x1b[>11u", c.out.String())
+	assert.Equal(t, KeyboardFlags(11), vx.KeyboardFlags())
+	// The whole stack is restored when resuming
+	assert.Equal(t, "\x1b[>29u\x1b[>11u", vx.pushKeyboardFlags())
+	assert.Equal(t, "\x1b[<2u", vx.popKeyboardFlags())
+
+	c.out.Reset()
+	vx.PopKeyboardFlags()
+	assert.Equal(t, "\x1b[<1u", c.out.String())
+	assert.Equal(t, defaultKeyboardFlags, vx.KeyboardFlags())
+	// The flags from Options are never popped
+	c.out.Reset()
+	vx.PopKeyboardFlags()
+	assert.Empty(t, c.out.String())
+	assert.Equal(t, defaultKeyboardFlags, vx.KeyboardFlags())
+
+	// Without kitty keyboard support, nothing is pushed
+	vx.caps.kittyKeyboard = false
+	vx.PushKeyboardFlags(KeyboardReportEvents)
+	assert.Empty(t, c.out.String())
+	assert.Equal(t, KeyboardFlags(0), vx.KeyboardFlags())
+}
+
+func TestQueryKeyboardFlags(t *testing.T) {
+	vx, c := newReplyVaxis("\x1b[?31u")
+	vx.caps.kittyKeyboard = true
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	flags, err := vx.QueryKeyboardFlags(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, defaultKeyboardFlags|KeyboardReportEvents, flags)
+	assert.Equal(t, "\x1b[?u", c.out.String())
+
+	// Terminals without the protocol don't reply
+	vx, c = newReplyVaxis("")
+	flags, err = vx.QueryKeyboardFlags(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, KeyboardFlags(0), flags)
+	assert.Empty(t, c.out.String())
+
+	vx.caps.kittyKeyboard = true
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = vx.QueryKeyboardFlags(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestKeyboardFlagsReport(t *testing.T) {
+	vx := &Vaxis{queue: make(chan Event, 16)}
+	// The reply to our startup query reports support for the protocol
+	atomicStore(&vx.detecting, true)
+	vx.handleKeyboardFlags(defaultKeyboardFlags)
+	assert.Equal(t, kittyKeyboard{}, <-vx.queue)
+
+	// Late replies never reach the application
+	atomicStore(&vx.detecting, false)
+	vx.handleKeyboardFlags(defaultKeyboardFlags)
+	assert.Empty(t, vx.queue)
+}
+
+func TestKeyboardReleaseEvents(t *testing.T) {
+	vx := &Vaxis{queue: make(chan Event, 16)}
+	// Enter, Tab and Backspace with KeyboardAllKeys and KeyboardReportEvents
+	input := "\x1b[13;1:3u\x1b[9;1:1u\x1b[127;1:3u"
+	parser := ansi.NewParser(strings.NewReader(input))
+	for seq := range parser.Next() {
+		if _, ok := seq.(ansi.EOF); ok {
+			break
+		}
+		vx.handleSequence(seq)
+	}
+	for _, expected := range []Key{
+		{Keycode: KeyEnter, EventType: EventRelease},
+		{Keycode: KeyTab, EventType: EventPress},
+		{Keycode: KeyBackspace, EventType: EventRelease},
+	} {
+		ev := (<-vx.queue).(Key)
+		assert.Equal(t, expected.Keycode, ev.Keycode)
+		assert.Equal(t, expected.EventType, ev.EventType)
+	}
+}
@@ -0,0 +1,229 @@
+package keymap
+
+import (
+	"time"
+
+	"git.sr.ht/~rockorager/vaxis"
+)
+
+// DefaultTimeout is how long a [Dispatcher] waits for the next key of a chord
+var DefaultTimeout = time.Second
+
+// Action is posted to the event loop when an action without a handler is
+// dispatched
+type Action struct {
+	// Name of the action
+	Name string
+	// Keys are the key events which triggered the action
+	Keys []vaxis.Key
+}
+
+// chordTimeout is posted when a pending chord times out
+type chordTimeout struct {
+	d   *Dispatcher
+	gen int
+}
+
+// Dispatcher dispatches key events to the actions bound in a stack of
+// keymaps, such as the modes of a modal editor. Keys are looked up in the
+// keymap on top of the stack first, then in each keymap below it until an
+// opaque keymap.
+//
+// When the keys pressed so far are the start of a chord, the Dispatcher waits
+// for the next key. If no key is pressed within the timeout, the chord is
+// abandoned, or if the keys so far are bound themselves, their action is
+// dispatched. Every event must be passed to Update for timeouts to work
+type Dispatcher struct {
+	// Timeout is how long to wait for the next key of a chord. Zero uses
+	// DefaultTimeout
+	Timeout time.Duration
+
+	vx       *vaxis.Vaxis
+	stack    []*Keymap
+	handlers map[string]func()
+	pending  []vaxis.Key
+	// action is the action bound to the pending keys, if any
+	action string
+	timer  *vaxis.Timer
+	// gen identifies the current timeout, so stale ones are ignored
+	gen int
+}
+
+// NewDispatcher creates a Dispatcher with base at the bottom of its stack. vx
+// is used to time out chords, if it is nil chords wait until the next key
+func NewDispatcher(vx *vaxis.Vaxis, base *Keymap) *Dispatcher {
+	return &Dispatcher{
+		vx:       vx,
+		stack:    []*Keymap{base},
+		handlers: make(map[string]func()),
+	}
+}
+
+// Push makes m the current mode. Any pending chord is abandoned
+func (d *Dispatcher) Push(m *Keymap) {
+	d.reset()
+	d.stack = append(d.stack, m)
+}
+
+// Pop removes the current mode and returns it. The base keymap is never
+// removed: Pop returns nil if it is the only keymap. Any pending chord is
+// abandoned
+func (d *Dispatcher) Pop() *Keymap {
+	if len(d.stack) == 1 {
+		return nil
+	}
+	d.reset()
+	m := d.stack[len(d.stack)-1]
+	d.stack = d.stack[:len(d.stack)-1]
+	return m
+}
+
+// Mode returns the keymap on top of the stack
+func (d *Dispatcher) Mode() *Keymap {
+	return d.stack[len(d.stack)-1]
+}
+
+// Handle sets the function called when action is dispatched. Actions without a
+// handler are posted to the event loop as an [Action]
+func (d *Dispatcher) Handle(action string, fn func()) {
+	d.handlers[action] = fn
+}
+
+// Pending returns the keys of the chord being entered, for example to show in
+// a status line
+func (d *Dispatcher) Pending() Binding {
+	return Binding(d.pending)
+}
+
+// Update handles an event. Update returns true if the event was a key which
+// was consumed by a binding, or a timeout of the Dispatcher. Keys which aren't
+// bound are not consumed, so the application can handle them
+func (d *Dispatcher) Update(ev vaxis.Event) bool {
+	switch ev := ev.(type) {
+	case chordTimeout:
+		if ev.d != d {
+			return false
+		}
+		if ev.gen == d.gen && len(d.pending) > 0 {
+			d.flush()
+		}
+		return true
+	case vaxis.Key:
+		if ev.EventType == vaxis.EventRelease || ev.EventType == vaxis.EventPaste {
+			return false
+		}
+		return d.key(ev)
+	}
+	return false
+}
+
+func (d *Dispatcher) key(ev vaxis.Key) bool {
+	keys := append(append([]vaxis.Key{}, d.pending...), ev)
+	n := d.find(keys)
+	switch {
+	case n == nil && len(d.pending) == 0:
+		return false
+	case n == nil:
+		// The chord was broken. If the keys so far are bound, that's
+		// what the user meant and the key starts over. Otherwise the
+		// chord and key are dropped
+		if d.action == "" {
+			d.reset()
+			return true
+		}
+		d.flush()
+		return d.key(ev)
+	case len(n.children) == 0:
+		d.reset()
+		d.dispatch(n.action, keys)
+		return true
+	default:
+		d.stopTimer()
+		d.pending = keys
+		d.action = n.action
+		if d.vx != nil {
+			d.gen += 1
+			d.timer = d.vx.After(d.timeout(), chordTimeout{d: d, gen: d.gen})
+		}
+		return true
+	}
+}
+
+// find looks up the keys in the stack of keymaps
+func (d *Dispatcher) find(keys []vaxis.Key) *node {
+	for i := len(d.stack) - 1; i >= 0; i -= 1 {
+		m := d.stack[i]
+		if n := m.find(keys); n != nil {
+			return n
+		}
+		if m.Opaque {
+			break
+		}
+	}
+	return nil
+}
+
+// flush dispatches the action bound to the pending keys, if any
+func (d *Dispatcher) flush() {
+	action := d.action
+	keys := d.pending
+	d.reset()
+	if action != "" {
+		d.dispatch(action, keys)
+	}
+}
+
+func (d *Dispatcher) dispatch(action string, keys []vaxis.Key) {
+	if fn, ok := d.handlers[action]; ok {
+		fn()
+		return
+	}
+	if d.vx != nil {
+		d.vx.PostEvent(Action{Name: action, Keys: keys})
+	}
+}
+
+// reset abandons any pending chord
+func (d *Dispatcher) reset() {
+	d.stopTimer()
+	d.pending = nil
+	d.action = ""
+}
+
+func (d *Dispatcher) stopTimer() {
+	if d.timer != nil {
+		d.timer.Stop()
+		d.timer = nil
+	}
+}
+
+func (d *Dispatcher) timeout() time.Duration {
+	if d.Timeout == 0 {
+		return DefaultTimeout
+	}
+	return d.Timeout
+}
+
+// Bindings returns the bindings in effect, for example to list on a help
+// screen. Bindings of the current mode are first, followed by each keymap
+// below it until an opaque keymap. Bindings hidden by a binding of the same
+// keys in a keymap above are omitted
+func (d *Dispatcher) Bindings() []Entry {
+	entries := []Entry{}
+	for i := len(d.stack) - 1; i >= 0; i -= 1 {
+		m := d.stack[i]
+	outer:
+		for _, entry := range m.entries {
+			for _, seen := range entries {
+				if seen.Keys.equal(entry.Keys) {
+					continue outer
+				}
+			}
+			entries = append(entries, entry)
+		}
+		if m.Opaque {
+			break
+		}
+	}
+	return entries
+}
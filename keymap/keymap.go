@@ -0,0 +1,267 @@
+// Package keymap binds keys to named actions. Bindings are written with the
+// syntax of [vaxis.Key.String], with the keys of a chord separated by spaces
+// ("Ctrl+x Ctrl+s"). A [Keymap] holds the bindings of one mode, and a
+// [Dispatcher] dispatches key events to the actions bound in a stack of
+// keymaps
+package keymap
+
+import (
+	"fmt"
+	"strings"
+
+	"git.sr.ht/~rockorager/vaxis"
+)
+
+// Binding is a sequence of keys. A Binding with more than one key is a chord
+type Binding []vaxis.Key
+
+// Parse parses a binding. Each key is parsed with [vaxis.ParseKey], and keys
+// are separated by spaces
+func Parse(s string) (Binding, error) {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("keymap: empty binding")
+	}
+	b := make(Binding, 0, len(fields))
+	for _, field := range fields {
+		key, err := vaxis.ParseKey(field)
+		if err != nil {
+			return nil, err
+		}
+		b = append(b, key)
+	}
+	return b, nil
+}
+
+// String returns the binding in the form accepted by [Parse]
+func (b Binding) String() string {
+	keys := make([]string, 0, len(b))
+	for _, key := range b {
+		keys = append(keys, key.String())
+	}
+	return strings.Join(keys, " ")
+}
+
+// MarshalText implements [encoding.TextMarshaler]
+func (b Binding) MarshalText() ([]byte, error) {
+	return []byte(b.String()), nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler] with [Parse]
+func (b *Binding) UnmarshalText(text []byte) error {
+	binding, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+	*b = binding
+	return nil
+}
+
+// equal reports if the bindings are made of the same keys
+func (b Binding) equal(other Binding) bool {
+	if len(b) != len(other) {
+		return false
+	}
+	for i := range b {
+		if !sameKey(b[i], other[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func sameKey(a vaxis.Key, b vaxis.Key) bool {
+	return a.Keycode == b.Keycode && a.Modifiers == b.Modifiers
+}
+
+// Entry is an action and the keys bound to it
+type Entry struct {
+	Keys   Binding
+	Action string
+	// Mode is the name of the keymap the entry is from
+	Mode string
+}
+
+// Conflict is a binding which is also the start of a longer chord. When its
+// keys are pressed, the action isn't dispatched until the chord times out
+type Conflict struct {
+	Binding Entry
+	Chord   Entry
+}
+
+// node is a node of the trie of bindings. Each node is one key of a chord
+type node struct {
+	key      vaxis.Key
+	action   string
+	children []*node
+}
+
+// child returns the child bound to the exact key
+func (n *node) child(key vaxis.Key) *node {
+	for _, c := range n.children {
+		if sameKey(c.key, key) {
+			return c
+		}
+	}
+	return nil
+}
+
+// match returns the child which matches the key event
+func (n *node) match(ev vaxis.Key) *node {
+	for _, c := range n.children {
+		if ev.Matches(c.key.Keycode, c.key.Modifiers) {
+			return c
+		}
+	}
+	return nil
+}
+
+// Keymap binds keys to named actions. A Keymap is typically the bindings of a
+// mode
+type Keymap struct {
+	// Name of the keymap, such as the name of the mode
+	Name string
+	// Opaque keymaps hide the keymaps below them in a [Dispatcher]. By
+	// default, keys which aren't bound are looked up in the keymap below
+	Opaque bool
+
+	root    node
+	entries []Entry
+}
+
+// New creates an empty keymap
+func New(name string) *Keymap {
+	return &Keymap{Name: name}
+}
+
+// Bind binds keys to action. An error is returned if the keys can't be parsed
+// or are already bound to a different action
+func (m *Keymap) Bind(keys string, action string) error {
+	b, err := Parse(keys)
+	if err != nil {
+		return err
+	}
+	return m.BindKeys(b, action)
+}
+
+// BindKeys binds a parsed binding to action. An error is returned if the
+// keys are already bound to a different action
+func (m *Keymap) BindKeys(b Binding, action string) error {
+	if len(b) == 0 {
+		return fmt.Errorf("keymap: empty binding")
+	}
+	if action == "" {
+		return fmt.Errorf("keymap: empty action for %s", b)
+	}
+	n := &m.root
+	for _, key := range b {
+		c := n.child(key)
+		if c == nil {
+			c = &node{key: key}
+			n.children = append(n.children, c)
+		}
+		n = c
+	}
+	switch n.action {
+	case "":
+	case action:
+		return nil
+	default:
+		return fmt.Errorf("keymap: %s is already bound to %q in %q", b, n.action, m.Name)
+	}
+	n.action = action
+	m.entries = append(m.entries, Entry{Keys: b, Action: action, Mode: m.Name})
+	return nil
+}
+
+// Unbind removes the binding of keys. Unbind returns false if the keys
+// weren't bound
+func (m *Keymap) Unbind(keys string) (bool, error) {
+	b, err := Parse(keys)
+	if err != nil {
+		return false, err
+	}
+	n := &m.root
+	path := []*node{n}
+	for _, key := range b {
+		n = n.child(key)
+		if n == nil {
+			return false, nil
+		}
+		path = append(path, n)
+	}
+	if n.action == "" {
+		return false, nil
+	}
+	n.action = ""
+	// Remove the nodes which no longer lead to a binding
+	for i := len(path) - 1; i > 0; i -= 1 {
+		c := path[i]
+		if c.action != "" || len(c.children) > 0 {
+			break
+		}
+		parent := path[i-1]
+		for j, sibling := range parent.children {
+			if sibling == c {
+				parent.children = append(parent.children[:j], parent.children[j+1:]...)
+				break
+			}
+		}
+	}
+	for i, entry := range m.entries {
+		if entry.Keys.equal(b) {
+			m.entries = append(m.entries[:i], m.entries[i+1:]...)
+			break
+		}
+	}
+	return true, nil
+}
+
+// Lookup returns the action bound to the keys, if any
+func (m *Keymap) Lookup(keys string) (string, bool) {
+	b, err := Parse(keys)
+	if err != nil {
+		return "", false
+	}
+	n := &m.root
+	for _, key := range b {
+		n = n.child(key)
+		if n == nil {
+			return "", false
+		}
+	}
+	return n.action, n.action != ""
+}
+
+// Bindings returns the bindings of the keymap, in the order they were bound
+func (m *Keymap) Bindings() []Entry {
+	entries := make([]Entry, len(m.entries))
+	copy(entries, m.entries)
+	return entries
+}
+
+// Conflicts returns the bindings which are also the start of a longer chord
+func (m *Keymap) Conflicts() []Conflict {
+	conflicts := []Conflict{}
+	for _, entry := range m.entries {
+		for _, other := range m.entries {
+			if len(other.Keys) > len(entry.Keys) && other.Keys[:len(entry.Keys)].equal(entry.Keys) {
+				conflicts = append(conflicts, Conflict{Binding: entry, Chord: other})
+			}
+		}
+	}
+	return conflicts
+}
+
+// find walks the trie along the key events. nil is returned if the keys
+// aren't bound, or aren't the start of a chord
+func (m *Keymap) find(keys []vaxis.Key) *node {
+	n := &m.root
+	for _, key := range keys {
+		n = n.match(key)
+		if n == nil {
+			return nil
+		}
+	}
+	return n
+}
@@ -0,0 +1,239 @@
+package keymap
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"git.sr.ht/~rockorager/vaxis"
+	"git.sr.ht/~rockorager/vaxis/vaxistest"
+)
+
+func ctrl(r rune) vaxis.Key {
+	return vaxis.Key{Keycode: r, Modifiers: vaxis.ModCtrl}
+}
+
+func key(r rune) vaxis.Key {
+	return vaxis.Key{Keycode: r, Text: string(r)}
+}
+
+func TestParse(t *testing.T) {
+	b, err := Parse("Ctrl+x  Ctrl+s")
+	require.NoError(t, err)
+	assert.Equal(t, Binding{ctrl('x'), ctrl('s')}, b)
+	assert.Equal(t, "Ctrl+x Ctrl+s", b.String())
+
+	b, err = Parse("g g")
+	require.NoError(t, err)
+	assert.Equal(t, "g g", b.String())
+
+	_, err = Parse("")
+	assert.Error(t, err)
+	_, err = Parse("Ctrl+x Fn+s")
+	assert.Error(t, err)
+
+	var text Binding
+	require.NoError(t, text.UnmarshalText([]byte("Alt+Enter")))
+	assert.Equal(t, Binding{{Keycode: vaxis.KeyEnter, Modifiers: vaxis.ModAlt}}, text)
+}
+
+func TestKeymap(t *testing.T) {
+	m := New("global")
+	require.NoError(t, m.Bind("Ctrl+x Ctrl+s", "save"))
+	require.NoError(t, m.Bind("Ctrl+x Ctrl+c", "quit"))
+	require.NoError(t, m.Bind("Ctrl+x", "prefix"))
+	// Rebinding the same action is fine, a different one conflicts
+	require.NoError(t, m.Bind("ctrl+x ctrl+s", "save"))
+	assert.Error(t, m.Bind("Ctrl+x Ctrl+s", "write"))
+	assert.Error(t, m.Bind("Ctrl+q", ""))
+
+	action, ok := m.Lookup("Ctrl+x Ctrl+s")
+	assert.True(t, ok)
+	assert.Equal(t, "save", action)
+
+	assert.Equal(t, []Conflict{
+		{
+			Binding: Entry{Keys: Binding{ctrl('x')}, Action: "prefix", Mode: "global"},
+			Chord:   Entry{Keys: Binding{ctrl('x'), ctrl('s')}, Action: "save", Mode: "global"},
+		},
+		{
+			Binding: Entry{Keys: Binding{ctrl('x')}, Action: "prefix", Mode: "global"},
+			Chord:   Entry{Keys: Binding{ctrl('x'), ctrl('c')}, Action: "quit", Mode: "global"},
+		},
+	}, m.Conflicts())
+
+	ok, err := m.Unbind("Ctrl+x Ctrl+s")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = m.Unbind("Ctrl+x Ctrl+s")
+	require.NoError(t, err)
+	assert.False(t, ok)
+	_, ok = m.Lookup("Ctrl+x Ctrl+s")
+	assert.False(t, ok)
+	assert.Equal(t, []Entry{
+		{Keys: Binding{ctrl('x'), ctrl('c')}, Action: "quit", Mode: "global"},
+		{Keys: Binding{ctrl('x')}, Action: "prefix", Mode: "global"},
+	}, m.Bindings())
+
+	// Unbinding the last chord leaves nothing behind
+	_, _ = m.Unbind("Ctrl+x Ctrl+c")
+	_, _ = m.Unbind("Ctrl+x")
+	assert.Empty(t, m.root.children)
+}
+
+// record returns a dispatcher and the actions it has dispatched
+func record(vx *vaxis.Vaxis, base *Keymap, actions ...string) (*Dispatcher, *[]string) {
+	d := NewDispatcher(vx, base)
+	got := &[]string{}
+	for _, action := range actions {
+		action := action
+		d.Handle(action, func() {
+			*got = append(*got, action)
+		})
+	}
+	return d, got
+}
+
+func TestDispatcher(t *testing.T) {
+	m := New("global")
+	require.NoError(t, m.Bind("Ctrl+x Ctrl+s", "save"))
+	require.NoError(t, m.Bind("Ctrl+q", "quit"))
+	require.NoError(t, m.Bind(":", "command"))
+	d, got := record(nil, m, "save", "quit", "command")
+
+	assert.False(t, d.Update(key('a')))
+	assert.True(t, d.Update(ctrl('x')))
+	assert.Equal(t, "Ctrl+x", d.Pending().String())
+	assert.Empty(t, *got)
+	assert.True(t, d.Update(ctrl('s')))
+	assert.Empty(t, d.Pending())
+	assert.Equal(t, []string{"save"}, *got)
+
+	// Releases are ignored
+	assert.False(t, d.Update(vaxis.Key{Keycode: 'q', Modifiers: vaxis.ModCtrl, EventType: vaxis.EventRelease}))
+	assert.True(t, d.Update(ctrl('q')))
+	// Keys match the same way as Key.Matches
+	assert.True(t, d.Update(vaxis.Key{Keycode: ';', ShiftedCode: ':', Modifiers: vaxis.ModShift}))
+	assert.Equal(t, []string{"save", "quit", "command"}, *got)
+
+	// A broken chord is dropped with the key that broke it
+	*got = nil
+	assert.True(t, d.Update(ctrl('x')))
+	assert.True(t, d.Update(ctrl('q')))
+	assert.Empty(t, *got)
+	assert.Empty(t, d.Pending())
+}
+
+func TestDispatcherAmbiguous(t *testing.T) {
+	m := New("normal")
+	require.NoError(t, m.Bind("g", "goto"))
+	require.NoError(t, m.Bind("g g", "top"))
+	d, got := record(nil, m, "goto", "top")
+
+	assert.True(t, d.Update(key('g')))
+	assert.True(t, d.Update(key('g')))
+	assert.Equal(t, []string{"top"}, *got)
+
+	// Breaking the chord dispatches the shorter binding, and the next key
+	// starts over
+	*got = nil
+	assert.True(t, d.Update(key('g')))
+	assert.True(t, d.Update(key('g')))
+	assert.True(t, d.Update(key('g')))
+	assert.Equal(t, []string{"top"}, *got)
+	assert.False(t, d.Update(key('x')))
+	assert.Equal(t, []string{"top", "goto"}, *got)
+}
+
+func TestDispatcherModes(t *testing.T) {
+	global := New("global")
+	require.NoError(t, global.Bind("Ctrl+c", "quit"))
+	require.NoError(t, global.Bind("Escape", "cancel"))
+	normal := New("normal")
+	require.NoError(t, normal.Bind("i", "insert"))
+	require.NoError(t, normal.Bind("Escape", "bell"))
+	insert := New("insert")
+	insert.Opaque = true
+	require.NoError(t, insert.Bind("Escape", "normal"))
+	d, got := record(nil, global, "quit", "cancel", "insert", "bell", "normal")
+
+	d.Push(normal)
+	assert.Equal(t, normal, d.Mode())
+	assert.True(t, d.Update(vaxis.Key{Keycode: vaxis.KeyEsc}))
+	assert.True(t, d.Update(ctrl('c')))
+	assert.True(t, d.Update(key('i')))
+	assert.Equal(t, []string{"bell", "quit", "insert"}, *got)
+	assert.Equal(t, []Entry{
+		{Keys: Binding{{Keycode: 'i'}}, Action: "insert", Mode: "normal"},
+		{Keys: Binding{{Keycode: vaxis.KeyEsc}}, Action: "bell", Mode: "normal"},
+		{Keys: Binding{ctrl('c')}, Action: "quit", Mode: "global"},
+	}, d.Bindings())
+
+	// The insert keymap is opaque, so typed keys aren't bound
+	*got = nil
+	d.Push(insert)
+	assert.False(t, d.Update(key('i')))
+	assert.False(t, d.Update(ctrl('c')))
+	assert.True(t, d.Update(vaxis.Key{Keycode: vaxis.KeyEsc}))
+	assert.Equal(t, []string{"normal"}, *got)
+	assert.Equal(t, []Entry{
+		{Keys: Binding{{Keycode: vaxis.KeyEsc}}, Action: "normal", Mode: "insert"},
+	}, d.Bindings())
+
+	assert.Equal(t, insert, d.Pop())
+	assert.Equal(t, normal, d.Pop())
+	assert.Nil(t, d.Pop())
+	assert.Equal(t, global, d.Mode())
+}
+
+func TestDispatcherTimeout(t *testing.T) {
+	tt, err := vaxistest.New(20, 5, vaxis.Options{})
+	require.NoError(t, err)
+	defer tt.Close()
+
+	m := New("normal")
+	require.NoError(t, m.Bind("g", "goto"))
+	require.NoError(t, m.Bind("g g", "top"))
+	require.NoError(t, m.Bind("z z", "center"))
+	d, got := record(tt.Vx, m, "goto")
+	d.Timeout = 10 * time.Millisecond
+
+	// run passes events to the dispatcher until done returns true
+	run := func(done func(ev vaxis.Event) bool) bool {
+		for {
+			ev := tt.NextEvent(time.Second)
+			if ev == nil {
+				return false
+			}
+			d.Update(ev)
+			if done(ev) {
+				return true
+			}
+		}
+	}
+
+	// After the timeout, the shorter binding is dispatched
+	assert.True(t, d.Update(key('g')))
+	require.True(t, run(func(vaxis.Event) bool { return len(*got) > 0 }))
+	assert.Equal(t, []string{"goto"}, *got)
+	assert.Empty(t, d.Pending())
+
+	// A chord without a shorter binding is abandoned
+	assert.True(t, d.Update(key('z')))
+	require.True(t, run(func(vaxis.Event) bool { return len(d.Pending()) == 0 }))
+	assert.Equal(t, []string{"goto"}, *got)
+
+	// Actions without a handler are posted
+	assert.True(t, d.Update(key('z')))
+	assert.True(t, d.Update(key('z')))
+	var action Action
+	require.True(t, run(func(ev vaxis.Event) bool {
+		var ok bool
+		action, ok = ev.(Action)
+		return ok
+	}))
+	assert.Equal(t, "center", action.Name)
+	assert.Equal(t, Binding{key('z'), key('z')}, Binding(action.Keys))
+}
@@ -1,17 +1,59 @@
 package vaxis
 
 import (
+	"sync/atomic"
+
 	"git.sr.ht/~rockorager/vaxis/ansi"
 	"git.sr.ht/~rockorager/vaxis/log"
 )
 
 // Mouse is a mouse event
 type Mouse struct {
-	Button    MouseButton
-	Row       int
-	Col       int
+	Button MouseButton
+	Row    int
+	Col    int
+	// XPixel and YPixel are the position of the pointer in pixels,
+	// relative to the same origin as Row and Col. They are only set
+	// when Options.MousePixels is set and the terminal supports it, see
+	// [Capabilities]. Row and Col are always set
+	XPixel    int
+	YPixel    int
 	EventType EventType
 	Modifiers ModifierMask
+	// Region is the ID of the region under the pointer, registered with
+	// [Window.Region], or nil. While a button pressed over a region is
+	// held, events are reported to that region even outside of it
+	Region any
+	// RegionCol and RegionRow are the position of the pointer relative to
+	// the region
+	RegionCol int
+	RegionRow int
+}
+
+// MouseMode selects which mouse events are reported
+type MouseMode int
+
+const (
+	// MouseAnyMotion reports button presses and releases, and all motion
+	// of the pointer
+	MouseAnyMotion MouseMode = iota
+	// MouseButtons reports button presses and releases
+	MouseButtons
+	// MouseDrag reports button presses and releases, and motion of the
+	// pointer while a button is pressed
+	MouseDrag
+)
+
+// mode returns the private mode which enables the tracking mode
+func (m MouseMode) mode() int {
+	switch m {
+	case MouseButtons:
+		return mouseButtonEvents
+	case MouseDrag:
+		return mouseDragEvents
+	default:
+		return mouseAllEvents
+	}
 }
 
 // MouseButton represents a mouse button
@@ -21,10 +63,15 @@ const (
 	MouseLeftButton MouseButton = iota
 	MouseMiddleButton
 	MouseRightButton
+	// MouseNoButton is reported for motion without a button pressed. It is
+	// also the button of releases in the X10 and URXVT encodings, which
+	// don't report which button was released
 	MouseNoButton
 
-	MouseWheelUp   MouseButton = 64
-	MouseWheelDown MouseButton = 65
+	MouseWheelUp    MouseButton = 64
+	MouseWheelDown  MouseButton = 65
+	MouseWheelLeft  MouseButton = 66
+	MouseWheelRight MouseButton = 67
 
 	MouseButton8  MouseButton = 128
 	MouseButton9  MouseButton = 129
@@ -56,40 +103,51 @@ const (
 	mouseModCtrl  = 0b00010000
 )
 
+// parseMouseEvent decodes a mouse report in the SGR encoding, CSI < Cb ; Cx ;
+// Cy M (or m for releases), or the URXVT encoding, CSI Cb ; Cx ; Cy M where Cb
+// is offset by 32. X10 reports are emitted by the parser in the URXVT form
 func parseMouseEvent(seq ansi.CSI) (Mouse, bool) {
 	mouse := Mouse{}
-	if len(seq.Intermediate) != 1 && seq.Intermediate[0] != '<' {
-		log.Error("[CSI] unknown sequence: %s", seq)
-		return mouse, false
-	}
-
 	if len(seq.Parameters) != 3 {
 		log.Error("[CSI] unknown sequence: %s", seq)
 		return mouse, false
 	}
 
-	switch seq.Final {
-	case 'M':
+	cb := seq.Parameters[0][0]
+	switch {
+	case len(seq.Intermediate) == 1 && seq.Intermediate[0] == '<':
+		switch seq.Final {
+		case 'M':
+			mouse.EventType = EventPress
+		case 'm':
+			mouse.EventType = EventRelease
+		}
+	case len(seq.Intermediate) == 0 && seq.Final == 'M':
+		cb -= 32
 		mouse.EventType = EventPress
-	case 'm':
-		mouse.EventType = EventRelease
+		if cb&buttonBits == int(MouseNoButton) && cb&motion == 0 {
+			mouse.EventType = EventRelease
+		}
+	default:
+		log.Error("[CSI] unknown sequence: %s", seq)
+		return mouse, false
 	}
 
 	// buttons are encoded with the high two and low two bits
-	button := seq.Parameters[0][0] & buttonBits
+	button := cb & buttonBits
 	mouse.Button = MouseButton(button)
 
-	if seq.Parameters[0][0]&motion != 0 {
+	if cb&motion != 0 {
 		mouse.EventType = EventMotion
 	}
 
-	if seq.Parameters[0][0]&mouseModShift != 0 {
+	if cb&mouseModShift != 0 {
 		mouse.Modifiers |= ModShift
 	}
-	if seq.Parameters[0][0]&mouseModAlt != 0 {
+	if cb&mouseModAlt != 0 {
 		mouse.Modifiers |= ModAlt
 	}
-	if seq.Parameters[0][0]&mouseModCtrl != 0 {
+	if cb&mouseModCtrl != 0 {
 		mouse.Modifiers |= ModCtrl
 	}
 
@@ -98,3 +156,35 @@ func parseMouseEvent(seq ansi.CSI) (Mouse, bool) {
 
 	return mouse, true
 }
+
+// storeCellSize stores the size of a cell in pixels from the size of the
+// window
+func (vx *Vaxis) storeCellSize(ws Resize) {
+	if ws.Cols == 0 || ws.Rows == 0 {
+		return
+	}
+	atomic.StoreInt32(&vx.cellWidth, int32(ws.XPixel/ws.Cols))
+	atomic.StoreInt32(&vx.cellHeight, int32(ws.YPixel/ws.Rows))
+}
+
+// pixelsToCells converts a mouse event reported in pixels, with SGR-Pixels,
+// to a mouse event with both the pixel and cell positions
+func (vx *Vaxis) pixelsToCells(mouse Mouse) Mouse {
+	mouse.XPixel = mouse.Col
+	mouse.YPixel = mouse.Row
+	if mouse.XPixel < 0 {
+		mouse.XPixel = 0
+	}
+	if mouse.YPixel < 0 {
+		mouse.YPixel = 0
+	}
+	mouse.Col = 0
+	mouse.Row = 0
+	if w := int(atomic.LoadInt32(&vx.cellWidth)); w > 0 {
+		mouse.Col = mouse.XPixel / w
+	}
+	if h := int(atomic.LoadInt32(&vx.cellHeight)); h > 0 {
+		mouse.Row = mouse.YPixel / h
+	}
+	return mouse
+}
@@ -0,0 +1,183 @@
+package vaxis
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"git.sr.ht/~rockorager/vaxis/ansi"
+)
+
+func TestParseMouseEvent(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected Mouse
+	}{
+		{
+			name:     "sgr press",
+			input:    "\x1b[<0;10;20M",
+			expected: Mouse{Button: MouseLeftButton, Col: 9, Row: 19, EventType: EventPress},
+		},
+		{
+			name:     "sgr release",
+			input:    "\x1b[<2;10;20m",
+			expected: Mouse{Button: MouseRightButton, Col: 9, Row: 19, EventType: EventRelease},
+		},
+		{
+			name:     "sgr motion",
+			input:    "\x1b[<35;1;1M",
+			expected: Mouse{Button: MouseNoButton, EventType: EventMotion},
+		},
+		{
+			name:     "sgr wheel left",
+			input:    "\x1b[<66;5;5M",
+			expected: Mouse{Button: MouseWheelLeft, Col: 4, Row: 4, EventType: EventPress},
+		},
+		{
+			name:     "sgr ctrl+wheel right",
+			input:    "\x1b[<83;5;5M",
+			expected: Mouse{Button: MouseWheelRight, Col: 4, Row: 4, EventType: EventPress, Modifiers: ModCtrl},
+		},
+		{
+			name:     "urxvt press",
+			input:    "\x1b[33;10;20M",
+			expected: Mouse{Button: MouseMiddleButton, Col: 9, Row: 19, EventType: EventPress},
+		},
+		{
+			name:     "urxvt release",
+			input:    "\x1b[35;10;20M",
+			expected: Mouse{Button: MouseNoButton, Col: 9, Row: 19, EventType: EventRelease},
+		},
+		{
+			name:     "urxvt drag",
+			input:    "\x1b[64;300;2M",
+			expected: Mouse{Button: MouseLeftButton, Col: 299, Row: 1, EventType: EventMotion},
+		},
+		{
+			name:     "urxvt wheel right",
+			input:    "\x1b[99;1;1M",
+			expected: Mouse{Button: MouseWheelRight, EventType: EventPress},
+		},
+		{
+			name:     "x10 press",
+			input:    "\x1b[M !!",
+			expected: Mouse{Button: MouseLeftButton, EventType: EventPress},
+		},
+		{
+			name:     "x10 shift+release",
+			input:    "\x1b[M'*5",
+			expected: Mouse{Button: MouseNoButton, Col: 9, Row: 20, EventType: EventRelease, Modifiers: ModShift},
+		},
+		{
+			name:     "x10 wheel up",
+			input:    "\x1b[M`\xff\x80",
+			expected: Mouse{Button: MouseWheelUp, Col: 222, Row: 95, EventType: EventPress},
+		},
+		{
+			name:     "x10 wheel left",
+			input:    "\x1b[Mb!!",
+			expected: Mouse{Button: MouseWheelLeft, EventType: EventPress},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			parser := ansi.NewInputParser(strings.NewReader(test.input))
+			seq, ok := (<-parser.Next()).(ansi.CSI)
+			assert.True(t, ok)
+			mouse, ok := parseMouseEvent(seq)
+			assert.True(t, ok)
+			assert.Equal(t, test.expected, mouse)
+		})
+	}
+
+	// CSI M without parameters isn't a mouse report
+	_, ok := parseMouseEvent(ansi.CSI{Final: 'M'})
+	assert.False(t, ok)
+	_, ok = parseMouseEvent(ansi.CSI{Final: 'M', Intermediate: []rune{'>'}, Parameters: [][]int{{0}, {1}, {1}}})
+	assert.False(t, ok)
+}
+
+func TestMouseMode(t *testing.T) {
+	assert.Equal(t, mouseAllEvents, MouseAnyMotion.mode())
+	assert.Equal(t, mouseButtonEvents, MouseButtons.mode())
+	assert.Equal(t, mouseDragEvents, MouseDrag.mode())
+}
+
+func TestMousePixels(t *testing.T) {
+	vx := &Vaxis{queue: make(chan Event, 16)}
+	atomicStore(&vx.mousePixels, true)
+	vx.storeCellSize(Resize{Cols: 80, Rows: 24, XPixel: 800, YPixel: 480})
+
+	input := "\x1b[<0;1;1M\x1b[<0;95;47m\x1b[<32;805;479M"
+	parser := ansi.NewParser(strings.NewReader(input))
+	for seq := range parser.Next() {
+		if _, ok := seq.(ansi.EOF); ok {
+			break
+		}
+		vx.handleSequence(seq)
+	}
+	assert.Equal(t, Mouse{
+		Button:    MouseLeftButton,
+		EventType: EventPress,
+	}, <-vx.queue)
+	assert.Equal(t, Mouse{
+		Button:    MouseLeftButton,
+		Col:       9,
+		Row:       2,
+		XPixel:    94,
+		YPixel:    46,
+		EventType: EventRelease,
+	}, <-vx.queue)
+	assert.Equal(t, Mouse{
+		Button:    MouseLeftButton,
+		Col:       80,
+		Row:       23,
+		XPixel:    804,
+		YPixel:    478,
+		EventType: EventMotion,
+	}, <-vx.queue)
+
+	// Inline, positions are relative to the inline region
+	vx.inline = &inlineState{anchor: 10}
+	parser = ansi.NewParser(strings.NewReader("\x1b[<0;11;221M"))
+	vx.handleSequence(<-parser.Next())
+	assert.Equal(t, Mouse{
+		Button:    MouseLeftButton,
+		Col:       1,
+		Row:       1,
+		XPixel:    10,
+		YPixel:    20,
+		EventType: EventPress,
+	}, <-vx.queue)
+}
+
+func TestInlineMouse(t *testing.T) {
+	vx := &Vaxis{queue: make(chan Event, 16)}
+	vx.inline = &inlineState{anchor: 10}
+
+	// Events above the region are dropped, except releases which are
+	// moved into the region
+	input := "\x1b[<0;3;5M\x1b[<32;4;6M\x1b[<0;5;7m\x1b[<0;6;12M"
+	parser := ansi.NewParser(strings.NewReader(input))
+	for seq := range parser.Next() {
+		if _, ok := seq.(ansi.EOF); ok {
+			break
+		}
+		vx.handleSequence(seq)
+	}
+	assert.Equal(t, Mouse{
+		Button:    MouseLeftButton,
+		Col:       4,
+		Row:       0,
+		EventType: EventRelease,
+	}, <-vx.queue)
+	assert.Equal(t, Mouse{
+		Button:    MouseLeftButton,
+		Col:       5,
+		Row:       1,
+		EventType: EventPress,
+	}, <-vx.queue)
+	assert.Empty(t, vx.queue)
+}
@@ -0,0 +1,289 @@
+package vaxis
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// colorSlot identifies a color the terminal reports: a palette index (OSC 4),
+// or the default foreground (OSC 10) or background (OSC 11)
+type colorSlot struct {
+	code  int
+	index int
+}
+
+// colorReport is the terminal's reply to a color query
+type colorReport struct {
+	slot  colorSlot
+	color Color
+}
+
+// parseColorReports parses the payload of an OSC 4, 10, 11 or 12 reply. A
+// reply may contain several colors: OSC 4 has pairs of indexes and colors,
+// and each additional color of the dynamic colors is for the next code (eg
+// "10;rgb:ffff/ffff/ffff;rgb:0000/0000/0000" is the foreground and
+// background)
+func parseColorReports(payload string) ([]colorReport, bool) {
+	vals := strings.Split(payload, ";")
+	code, err := strconv.Atoi(vals[0])
+	if err != nil {
+		return nil, false
+	}
+	reports := []colorReport{}
+	switch code {
+	case 4:
+		if len(vals)%2 != 1 {
+			return nil, false
+		}
+		for i := 1; i < len(vals); i += 2 {
+			index, err := strconv.Atoi(vals[i])
+			if err != nil || index < 0 || index > 255 {
+				return nil, false
+			}
+			color, ok := parseXColor(vals[i+1])
+			if !ok {
+				return nil, false
+			}
+			reports = append(reports, colorReport{
+				slot:  colorSlot{code: 4, index: index},
+				color: color,
+			})
+		}
+	case 10, 11, 12:
+		for i, val := range vals[1:] {
+			color, ok := parseXColor(val)
+			if !ok {
+				return nil, false
+			}
+			reports = append(reports, colorReport{
+				slot:  colorSlot{code: code + i},
+				color: color,
+			})
+		}
+	default:
+		return nil, false
+	}
+	return reports, len(reports) > 0
+}
+
+// parseXColor parses a color in the rgb:R/G/B format terminals reply with.
+// Each component is 1 to 4 hex digits, which we scale to 8 bits
+func parseXColor(s string) (Color, bool) {
+	if !strings.HasPrefix(s, "rgb:") {
+		return 0, false
+	}
+	parts := strings.Split(strings.TrimPrefix(s, "rgb:"), "/")
+	if len(parts) != 3 {
+		return 0, false
+	}
+	var channels [3]uint8
+	for i, part := range parts {
+		if len(part) < 1 || len(part) > 4 {
+			return 0, false
+		}
+		v, err := strconv.ParseUint(part, 16, 16)
+		if err != nil {
+			return 0, false
+		}
+		scale := uint64(1)<<(4*len(part)) - 1
+		channels[i] = uint8((v*255 + scale/2) / scale)
+	}
+	return RGBColor(channels[0], channels[1], channels[2]), true
+}
+
+// xcolor formats a color in the rgb:RR/GG/BB format. Indexed colors are
+// converted to their RGB values
+func (vx *Vaxis) xcolor(c Color) string {
+	v := uint32(c) & 0xFFFFFF
+	if c&indexed != 0 {
+		v = vx.indexRGB(int(uint8(c)))
+	}
+	return fmt.Sprintf("rgb:%02x/%02x/%02x", uint8(v>>16), uint8(v>>8), uint8(v))
+}
+
+// handleColorReports delivers the reports to a pending color query. Without
+// one, the reports are replies to the queries sent at startup, which are only
+// used while we wait for them. Later replies are dropped
+func (vx *Vaxis) handleColorReports(reports []colorReport) {
+	if atomic.LoadInt32(&vx.colorQueryPending) == 1 {
+		vx.postColorReports(reports)
+		return
+	}
+	if !atomicLoad(&vx.detecting) {
+		return
+	}
+	for _, report := range reports {
+		if report.slot.code == 11 {
+			vx.PostEvent(backgroundReport(report.color))
+		}
+	}
+}
+
+// postColorReports sends the reports to a pending color query. Reports
+// nobody is waiting for are dropped
+func (vx *Vaxis) postColorReports(reports []colorReport) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	for _, report := range reports {
+		select {
+		case vx.chColor <- report:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// queryColors writes the query and waits for the terminal to report each of
+// the slots
+func (vx *Vaxis) queryColors(ctx context.Context, query string, slots []colorSlot) (map[colorSlot]Color, error) {
+	vx.colorQuery.Lock()
+	defer vx.colorQuery.Unlock()
+	atomic.StoreInt32(&vx.colorQueryPending, 1)
+	defer atomic.StoreInt32(&vx.colorQueryPending, 0)
+	want := make(map[colorSlot]bool, len(slots))
+	for _, slot := range slots {
+		want[slot] = true
+	}
+	_, _ = io.WriteString(vx.console, query)
+	colors := make(map[colorSlot]Color, len(slots))
+	for len(colors) < len(want) {
+		select {
+		case report := <-vx.chColor:
+			if want[report.slot] {
+				colors[report.slot] = report.color
+			}
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	return colors, nil
+}
+
+// QueryPalette requests the 16 ANSI colors from the terminal. The colors are
+// also used when converting colors for lower color depths. Terminals which
+// don't support querying the palette never reply: callers should provide a
+// context with a deadline. An error is returned if the context is done before
+// the terminal replies
+func (vx *Vaxis) QueryPalette(ctx context.Context) ([16]Color, error) {
+	palette := [16]Color{}
+	query := strings.Builder{}
+	slots := make([]colorSlot, 0, len(palette))
+	for i := range palette {
+		query.WriteString(tparm(osc4query, i))
+		slots = append(slots, colorSlot{code: 4, index: i})
+	}
+	colors, err := vx.queryColors(ctx, query.String(), slots)
+	if err != nil {
+		return palette, err
+	}
+	values := make([]uint32, len(palette))
+	for i, slot := range slots {
+		palette[i] = colors[slot]
+		values[i] = uint32(palette[i]) & 0xFFFFFF
+	}
+	vx.mu.Lock()
+	vx.palette = values
+	vx.mu.Unlock()
+	return palette, nil
+}
+
+// QueryDefaultColors requests the default foreground and background colors
+// from the terminal. Terminals which don't support querying colors never
+// reply: callers should provide a context with a deadline. An error is
+// returned if the context is done before the terminal replies
+func (vx *Vaxis) QueryDefaultColors(ctx context.Context) (fg Color, bg Color, err error) {
+	fgSlot := colorSlot{code: 10}
+	bgSlot := colorSlot{code: 11}
+	colors, err := vx.queryColors(ctx, osc10query+osc11query, []colorSlot{fgSlot, bgSlot})
+	if err != nil {
+		return 0, 0, err
+	}
+	return colors[fgSlot], colors[bgSlot], nil
+}
+
+// SetPaletteColor sets the color of a palette index. Setting the default
+// color resets the index to the terminal's color. The new color is used when
+// converting colors for lower color depths. Palette changes are undone when
+// Vaxis is suspended or closed, and reapplied when it resumes
+func (vx *Vaxis) SetPaletteColor(index uint8, c Color) {
+	vx.mu.Lock()
+	defer vx.mu.Unlock()
+	if c&indexed != 0 {
+		// Resolve the index now, it may be changed later
+		c = HexColor(vx.indexRGB(int(uint8(c))))
+	}
+	vx.paletteColors[index] = c
+	if c == 0 {
+		_, _ = io.WriteString(vx.console, tparm(osc104reset, index))
+		return
+	}
+	_, _ = io.WriteString(vx.console, tparm(osc4set, index, vx.xcolor(c)))
+}
+
+// SetCursorColor sets the color of the hardware cursor. Setting the default
+// color resets the cursor to the terminal's color. The cursor color is reset
+// when Vaxis is suspended or closed, and reapplied when it resumes
+func (vx *Vaxis) SetCursorColor(c Color) {
+	vx.mu.Lock()
+	defer vx.mu.Unlock()
+	vx.cursorColor = c
+	if c == 0 {
+		_, _ = io.WriteString(vx.console, osc112reset)
+		return
+	}
+	_, _ = io.WriteString(vx.console, tparm(osc12set, vx.xcolor(c)))
+}
+
+// setColors returns the sequences to apply the colors set by the application
+func (vx *Vaxis) setColors() string {
+	vx.mu.Lock()
+	defer vx.mu.Unlock()
+	b := strings.Builder{}
+	for i, c := range vx.paletteColors {
+		if c != 0 {
+			b.WriteString(tparm(osc4set, i, vx.xcolor(c)))
+		}
+	}
+	if vx.cursorColor != 0 {
+		b.WriteString(tparm(osc12set, vx.xcolor(vx.cursorColor)))
+	}
+	return b.String()
+}
+
+// resetColors returns the sequences to reset the colors set by the
+// application to the terminal's colors
+func (vx *Vaxis) resetColors() string {
+	vx.mu.Lock()
+	defer vx.mu.Unlock()
+	b := strings.Builder{}
+	for i, c := range vx.paletteColors {
+		if c != 0 {
+			b.WriteString(tparm(osc104reset, i))
+		}
+	}
+	if vx.cursorColor != 0 {
+		b.WriteString(osc112reset)
+	}
+	return b.String()
+}
+
+// ColorThemeMode returns the terminal's color theme, as last reported by the
+// terminal or estimated from its background color. Zero is returned if the
+// theme is unknown
+func (vx *Vaxis) ColorThemeMode() ColorThemeMode {
+	return ColorThemeMode(atomic.LoadInt32(&vx.colorTheme))
+}
+
+// themeFromBackground estimates the color theme from the background color: a
+// background which contrasts more with black than white is light
+func themeFromBackground(bg Color) ColorThemeMode {
+	if bg.Contrast(HexColor(0x000000)) > bg.Contrast(HexColor(0xFFFFFF)) {
+		return LightMode
+	}
+	return DarkMode
+}
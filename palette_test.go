@@ -0,0 +1,231 @@
+package vaxis
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/containerd/console"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"git.sr.ht/~rockorager/vaxis/ansi"
+)
+
+// replyConsole records what is written to it, and answers each write with
+// reply as if it came from the terminal
+type replyConsole struct {
+	console.Console
+	vx    *Vaxis
+	reply string
+	out   bytes.Buffer
+}
+
+func (c *replyConsole) Write(p []byte) (int, error) {
+	c.out.Write(p)
+	go func() {
+		parser := ansi.NewParser(strings.NewReader(c.reply))
+		for seq := range parser.Next() {
+			if _, ok := seq.(ansi.EOF); ok {
+				return
+			}
+			c.vx.handleSequence(seq)
+		}
+	}()
+	return len(p), nil
+}
+
+func newReplyVaxis(reply string) (*Vaxis, *replyConsole) {
+	vx := &Vaxis{
+		chColor:         make(chan colorReport),
+		chKeyboardFlags: make(chan KeyboardFlags),
+	}
+	c := &replyConsole{vx: vx, reply: reply}
+	vx.console = c
+	return vx, c
+}
+
+func TestParseColorReports(t *testing.T) {
+	tests := []struct {
+		name     string
+		payload  string
+		expected []colorReport
+	}{
+		{
+			name:    "palette",
+			payload: "4;1;rgb:cdcd/0000/0000",
+			expected: []colorReport{
+				{slot: colorSlot{code: 4, index: 1}, color: RGBColor(0xCD, 0, 0)},
+			},
+		},
+		{
+			name:    "several palette entries",
+			payload: "4;0;rgb:00/00/00;255;rgb:ee/ee/ee",
+			expected: []colorReport{
+				{slot: colorSlot{code: 4, index: 0}, color: RGBColor(0, 0, 0)},
+				{slot: colorSlot{code: 4, index: 255}, color: RGBColor(0xEE, 0xEE, 0xEE)},
+			},
+		},
+		{
+			name:    "foreground",
+			payload: "10;rgb:ffff/ffff/ffff",
+			expected: []colorReport{
+				{slot: colorSlot{code: 10}, color: RGBColor(0xFF, 0xFF, 0xFF)},
+			},
+		},
+		{
+			name:    "foreground and background",
+			payload: "10;rgb:f/8/0;rgb:123/456/789",
+			expected: []colorReport{
+				{slot: colorSlot{code: 10}, color: RGBColor(0xFF, 0x88, 0)},
+				{slot: colorSlot{code: 11}, color: RGBColor(0x12, 0x45, 0x78)},
+			},
+		},
+		{
+			name:    "query",
+			payload: "11;?",
+		},
+		{
+			name:    "invalid index",
+			payload: "4;256;rgb:00/00/00",
+		},
+		{
+			name:    "missing color",
+			payload: "4;1",
+		},
+		{
+			name:    "other OSC",
+			payload: "52;c;Zm9v",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reports, ok := parseColorReports(test.payload)
+			assert.Equal(t, test.expected != nil, ok)
+			assert.Equal(t, test.expected, reports)
+		})
+	}
+}
+
+func TestQueryPalette(t *testing.T) {
+	reply := strings.Builder{}
+	for i, v := range defaultPalette {
+		// Replies are terminated with BEL or ST
+		reply.WriteString("\x1b]4;")
+		reply.WriteString(tparm("%d;rgb:%02x/%02x/%02x", i, uint8(v>>16), uint8(v>>8), uint8(v)))
+		if i%2 == 0 {
+			reply.WriteString("\a")
+		} else {
+			reply.WriteString("\x1b\\")
+		}
+	}
+	vx, c := newReplyVaxis(reply.String())
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	palette, err := vx.QueryPalette(ctx)
+	require.NoError(t, err)
+	for i, v := range defaultPalette {
+		assert.Equal(t, HexColor(v), palette[i])
+	}
+	assert.Equal(t, defaultPalette[:], vx.palette)
+	assert.True(t, strings.HasPrefix(c.out.String(), "\x1b]4;0;?\x1b\\\x1b]4;1;?\x1b\\"))
+}
+
+func TestQueryDefaultColors(t *testing.T) {
+	vx, c := newReplyVaxis("\x1b]10;rgb:dcdc/dcdc/cccc\x1b\\\x1b]11;rgb:3f3f/3f3f/3f3f\x1b\\")
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	fg, bg, err := vx.QueryDefaultColors(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, HexColor(0xDCDCCC), fg)
+	assert.Equal(t, HexColor(0x3F3F3F), bg)
+	assert.Equal(t, "\x1b]10;?\x1b\\\x1b]11;?\x1b\\", c.out.String())
+}
+
+func TestQueryUnsupported(t *testing.T) {
+	// The terminal doesn't reply, so the query times out
+	vx, _ := newReplyVaxis("")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, _, err := vx.QueryDefaultColors(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	_, err = vx.QueryPalette(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Nil(t, vx.palette)
+}
+
+func TestSetColors(t *testing.T) {
+	vx, c := newReplyVaxis("")
+	vx.SetPaletteColor(1, HexColor(0x112233))
+	vx.SetPaletteColor(200, IndexColor(9))
+	vx.SetCursorColor(HexColor(0xFF8000))
+	assert.Equal(t, "\x1b]4;1;rgb:11/22/33\x1b\\"+
+		"\x1b]4;200;rgb:ff/00/00\x1b\\"+
+		"\x1b]12;rgb:ff/80/00\x1b\\", c.out.String())
+
+	// Setting the default color resets the entry
+	c.out.Reset()
+	vx.SetPaletteColor(200, 0)
+	assert.Equal(t, "\x1b]104;200\x1b\\", c.out.String())
+
+	assert.Equal(t, "\x1b]4;1;rgb:11/22/33\x1b\\\x1b]12;rgb:ff/80/00\x1b\\", vx.setColors())
+	assert.Equal(t, "\x1b]104;1\x1b\\\x1b]112\x1b\\", vx.resetColors())
+}
+
+func TestSetPaletteColorCache(t *testing.T) {
+	vx, _ := newReplyVaxis("")
+	assert.Equal(t, "rgb:ff/d7/00", vx.xcolor(IndexColor(220)))
+
+	// The new color is used to convert colors, until it's reset
+	vx.SetPaletteColor(220, HexColor(0x123456))
+	assert.Equal(t, "rgb:12/34/56", vx.xcolor(IndexColor(220)))
+	assert.Equal(t, IndexColor(220), vx.quantize(HexColor(0x123456), ColorDepth256))
+	vx.SetPaletteColor(220, 0)
+	assert.Equal(t, "rgb:ff/d7/00", vx.xcolor(IndexColor(220)))
+
+	// Indexes are resolved when they're set
+	vx.SetPaletteColor(1, IndexColor(220))
+	vx.SetPaletteColor(220, HexColor(0x123456))
+	assert.Equal(t, "rgb:ff/d7/00", vx.xcolor(IndexColor(1)))
+}
+
+func TestColorThemeMode(t *testing.T) {
+	vx := &Vaxis{queue: make(chan Event, 16)}
+	assert.Equal(t, ColorThemeMode(0), vx.ColorThemeMode())
+
+	// Without a pending query, a background report is from our startup
+	// queries
+	atomicStore(&vx.detecting, true)
+	input := "\x1b[?997;2n\x1b]11;rgb:ffff/ffff/dddd\x1b\\"
+	parser := ansi.NewParser(strings.NewReader(input))
+	for seq := range parser.Next() {
+		if _, ok := seq.(ansi.EOF); ok {
+			break
+		}
+		vx.handleSequence(seq)
+	}
+	assert.Equal(t, LightMode, vx.ColorThemeMode())
+	assert.Equal(t, ColorThemeUpdate{Mode: LightMode}, <-vx.queue)
+	assert.Equal(t, backgroundReport(HexColor(0xFFFFDD)), <-vx.queue)
+
+	// Once startup is done, late replies never reach the application
+	atomicStore(&vx.detecting, false)
+	parser = ansi.NewParser(strings.NewReader("\x1b]11;rgb:0000/0000/0000\x1b\\"))
+	for seq := range parser.Next() {
+		if _, ok := seq.(ansi.EOF); ok {
+			break
+		}
+		vx.handleSequence(seq)
+	}
+	assert.Empty(t, vx.queue)
+}
+
+func TestThemeFromBackground(t *testing.T) {
+	assert.Equal(t, DarkMode, themeFromBackground(HexColor(0x000000)))
+	assert.Equal(t, DarkMode, themeFromBackground(HexColor(0x282C34)))
+	assert.Equal(t, DarkMode, themeFromBackground(IndexColor(4)))
+	assert.Equal(t, LightMode, themeFromBackground(HexColor(0xFDF6E3)))
+	assert.Equal(t, LightMode, themeFromBackground(HexColor(0xFFFFFF)))
+}
@@ -0,0 +1,224 @@
+package vaxis
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ansiColorNames are the names of the 8 ANSI colors. The bright colors are
+// prefixed with "bright"
+var ansiColorNames = [8]string{
+	"black",
+	"red",
+	"green",
+	"yellow",
+	"blue",
+	"magenta",
+	"cyan",
+	"white",
+}
+
+// ParseColor parses a color from a string. The following forms are accepted,
+// ignoring case:
+//
+//   - "default" or the empty string for the default color
+//   - "#rgb" or "#rrggbb" for RGB colors
+//   - "0" to "255" for palette indexes
+//   - The ANSI color names ("red") and their bright variants ("brightred"),
+//     which are palette indexes 0 to 15
+//   - Any other X11 color name ("cornflowerblue" or "cornflower blue"), which
+//     are RGB colors
+func ParseColor(s string) (Color, error) {
+	name := strings.ToLower(strings.TrimSpace(s))
+	switch {
+	case name == "", name == "default":
+		return 0, nil
+	case strings.HasPrefix(name, "#"):
+		hex := name[1:]
+		if len(hex) == 3 {
+			hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+		}
+		if len(hex) != 6 {
+			break
+		}
+		v, err := strconv.ParseUint(hex, 16, 32)
+		if err != nil {
+			break
+		}
+		return HexColor(uint32(v)), nil
+	case name[0] >= '0' && name[0] <= '9':
+		i, err := strconv.ParseUint(name, 10, 8)
+		if err != nil {
+			break
+		}
+		return IndexColor(uint8(i)), nil
+	default:
+		name = strings.ReplaceAll(name, " ", "")
+		for i, ansi := range ansiColorNames {
+			switch name {
+			case ansi:
+				return IndexColor(uint8(i)), nil
+			case "bright" + ansi:
+				return IndexColor(uint8(i + 8)), nil
+			}
+		}
+		if v, ok := x11Colors[name]; ok {
+			return HexColor(v), nil
+		}
+	}
+	return 0, fmt.Errorf("vaxis: invalid color: %q", s)
+}
+
+// String returns the color in a form accepted by [ParseColor]: "default", a
+// palette index as an ANSI color name or number, or "#rrggbb"
+func (c Color) String() string {
+	switch {
+	case c.IsRGB():
+		r, g, b := c.RGB()
+		return fmt.Sprintf("#%02x%02x%02x", r, g, b)
+	case c.IsIndex():
+		i := uint8(c)
+		switch {
+		case i < 8:
+			return ansiColorNames[i]
+		case i < 16:
+			return "bright" + ansiColorNames[i-8]
+		}
+		return strconv.Itoa(int(i))
+	default:
+		return "default"
+	}
+}
+
+// attributeNames are the names of the attributes, in the order of their bits
+var attributeNames = []struct {
+	name string
+	attr AttributeMask
+}{
+	{name: "bold", attr: AttrBold},
+	{name: "dim", attr: AttrDim},
+	{name: "italic", attr: AttrItalic},
+	{name: "blink", attr: AttrBlink},
+	{name: "reverse", attr: AttrReverse},
+	{name: "invisible", attr: AttrInvisible},
+	{name: "strikethrough", attr: AttrStrikethrough},
+}
+
+// underlineNames are the names of the underline styles, by their value
+var underlineNames = []string{
+	UnderlineOff:    "off",
+	UnderlineSingle: "single",
+	UnderlineDouble: "double",
+	UnderlineCurly:  "curly",
+	UnderlineDotted: "dotted",
+	UnderlineDashed: "dashed",
+}
+
+// ParseStyle parses a style from a list of space separated words, such as
+// "bold italic fg:#ff0 bg:blue ul:curly ulcolor:red". The words are:
+//
+//   - The attributes: bold, dim, italic, blink, reverse, invisible and
+//     strikethrough
+//   - underline, which is the same as ul:single
+//   - fg:<color>, bg:<color> and ulcolor:<color> to set the foreground,
+//     background and underline colors. Colors are parsed with [ParseColor],
+//     and color names with spaces must be written without them
+//   - ul:<style> for the underline style: off, single, double, curly, dotted
+//     or dashed
+//   - link:<url> and linkparams:<params> for hyperlinks
+//
+// Words are case insensitive, except for hyperlinks. Later words override
+// earlier ones
+func ParseStyle(s string) (Style, error) {
+	style := Style{}
+	for _, word := range strings.Fields(s) {
+		key, val, hasVal := strings.Cut(word, ":")
+		if !hasVal {
+			key = strings.ToLower(word)
+			if key == "underline" {
+				style.UnderlineStyle = UnderlineSingle
+				continue
+			}
+			attr, ok := parseAttribute(key)
+			if !ok {
+				return Style{}, fmt.Errorf("vaxis: invalid style attribute: %q", word)
+			}
+			style.Attribute |= attr
+			continue
+		}
+		var err error
+		switch strings.ToLower(key) {
+		case "fg":
+			style.Foreground, err = ParseColor(val)
+		case "bg":
+			style.Background, err = ParseColor(val)
+		case "ulcolor":
+			style.UnderlineColor, err = ParseColor(val)
+		case "ul":
+			ul, ok := parseUnderline(strings.ToLower(val))
+			if !ok {
+				err = fmt.Errorf("vaxis: invalid underline style: %q", val)
+			}
+			style.UnderlineStyle = ul
+		case "link":
+			style.Hyperlink = val
+		case "linkparams":
+			style.HyperlinkParams = val
+		default:
+			err = fmt.Errorf("vaxis: invalid style: %q", word)
+		}
+		if err != nil {
+			return Style{}, err
+		}
+	}
+	return style, nil
+}
+
+func parseAttribute(name string) (AttributeMask, bool) {
+	for _, a := range attributeNames {
+		if a.name == name {
+			return a.attr, true
+		}
+	}
+	return 0, false
+}
+
+func parseUnderline(name string) (UnderlineStyle, bool) {
+	for ul, n := range underlineNames {
+		if n == name {
+			return UnderlineStyle(ul), true
+		}
+	}
+	return 0, false
+}
+
+// String returns the style in the form accepted by [ParseStyle]. The zero
+// style is the empty string
+func (s Style) String() string {
+	words := []string{}
+	for _, a := range attributeNames {
+		if s.Attribute&a.attr != 0 {
+			words = append(words, a.name)
+		}
+	}
+	if s.Foreground != 0 {
+		words = append(words, "fg:"+s.Foreground.String())
+	}
+	if s.Background != 0 {
+		words = append(words, "bg:"+s.Background.String())
+	}
+	if s.UnderlineStyle != UnderlineOff && int(s.UnderlineStyle) < len(underlineNames) {
+		words = append(words, "ul:"+underlineNames[s.UnderlineStyle])
+	}
+	if s.UnderlineColor != 0 {
+		words = append(words, "ulcolor:"+s.UnderlineColor.String())
+	}
+	if s.Hyperlink != "" {
+		words = append(words, "link:"+s.Hyperlink)
+	}
+	if s.HyperlinkParams != "" {
+		words = append(words, "linkparams:"+s.HyperlinkParams)
+	}
+	return strings.Join(words, " ")
+}
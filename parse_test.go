@@ -0,0 +1,141 @@
+package vaxis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Color
+	}{
+		{input: "", expected: 0},
+		{input: "default", expected: 0},
+		{input: "#ff8800", expected: HexColor(0xFF8800)},
+		{input: "#FF8800", expected: HexColor(0xFF8800)},
+		{input: "#f80", expected: HexColor(0xFF8800)},
+		{input: "238", expected: IndexColor(238)},
+		{input: "0", expected: IndexColor(0)},
+		{input: "red", expected: IndexColor(1)},
+		{input: "Red", expected: IndexColor(1)},
+		{input: "brightblue", expected: IndexColor(12)},
+		{input: "bright blue", expected: IndexColor(12)},
+		{input: "white", expected: IndexColor(7)},
+		{input: "cornflowerblue", expected: HexColor(0x6495ED)},
+		{input: "Cornflower Blue", expected: HexColor(0x6495ED)},
+		{input: "gray50", expected: HexColor(0x7F7F7F)},
+		{input: "orange", expected: HexColor(0xFFA500)},
+	}
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			c, err := ParseColor(test.input)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, c)
+		})
+	}
+
+	for _, input := range []string{"256", "-1", "#ff88", "#gg0000", "notacolor", "12abc"} {
+		_, err := ParseColor(input)
+		assert.Error(t, err, input)
+	}
+}
+
+func TestColorString(t *testing.T) {
+	tests := []struct {
+		color    Color
+		expected string
+	}{
+		{color: 0, expected: "default"},
+		{color: IndexColor(1), expected: "red"},
+		{color: IndexColor(15), expected: "brightwhite"},
+		{color: IndexColor(238), expected: "238"},
+		{color: HexColor(0x0A0B0C), expected: "#0a0b0c"},
+	}
+	for _, test := range tests {
+		t.Run(test.expected, func(t *testing.T) {
+			assert.Equal(t, test.expected, test.color.String())
+			c, err := ParseColor(test.color.String())
+			require.NoError(t, err)
+			assert.Equal(t, test.color, c)
+		})
+	}
+
+	// Every color round trips
+	for i := 0; i < 256; i += 1 {
+		c, err := ParseColor(IndexColor(uint8(i)).String())
+		require.NoError(t, err)
+		assert.Equal(t, IndexColor(uint8(i)), c)
+	}
+}
+
+func TestParseStyle(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected Style
+		str      string
+	}{
+		{
+			name:     "empty",
+			input:    "",
+			expected: Style{},
+			str:      "",
+		},
+		{
+			name:  "everything",
+			input: "bold italic fg:#ff0 bg:blue ul:curly ulcolor:red",
+			expected: Style{
+				Foreground:     HexColor(0xFFFF00),
+				Background:     IndexColor(4),
+				UnderlineColor: IndexColor(1),
+				UnderlineStyle: UnderlineCurly,
+				Attribute:      AttrBold | AttrItalic,
+			},
+			str: "bold italic fg:#ffff00 bg:blue ul:curly ulcolor:red",
+		},
+		{
+			name:  "underline",
+			input: "Underline  REVERSE\tfg:238",
+			expected: Style{
+				Foreground:     IndexColor(238),
+				UnderlineStyle: UnderlineSingle,
+				Attribute:      AttrReverse,
+			},
+			str: "reverse fg:238 ul:single",
+		},
+		{
+			name:  "hyperlink",
+			input: "link:https://example.com/Path linkparams:id=1",
+			expected: Style{
+				Hyperlink:       "https://example.com/Path",
+				HyperlinkParams: "id=1",
+			},
+			str: "link:https://example.com/Path linkparams:id=1",
+		},
+		{
+			name:     "later words override",
+			input:    "fg:red fg:green ul:double ul:off",
+			expected: Style{Foreground: IndexColor(2)},
+			str:      "fg:green",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			style, err := ParseStyle(test.input)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, style)
+			assert.Equal(t, test.str, style.String())
+			style, err = ParseStyle(style.String())
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, style)
+		})
+	}
+
+	for _, input := range []string{"loud", "fg:notacolor", "ul:wavy", "size:12"} {
+		_, err := ParseStyle(input)
+		assert.Error(t, err, input)
+	}
+}
@@ -1,24 +1,151 @@
 package vaxis
 
 import (
-	"os"
+	"strconv"
 	"strings"
 )
 
-func (vx *Vaxis) applyQuirks() {
-	// TODO: remove this when asciinema supports ':' delimiters in RGB
-	if os.Getenv("ASCIINEMA_REC") != "" {
-		fgIndexSet = strings.ReplaceAll(fgIndexSet, ":", ";")
-		fgRGBSet = strings.ReplaceAll(fgRGBSet, ":", ";")
-		bgIndexSet = strings.ReplaceAll(bgIndexSet, ":", ";")
-		bgRGBSet = strings.ReplaceAll(bgRGBSet, ":", ";")
-		// Asciinema also doesn't support any advanced image protocols
-		vx.graphicsProtocol = halfBlock
+// quirks are workarounds for terminal bugs
+type quirks struct {
+	// legacySGR separates the parameters of extended colors with ';'
+	// instead of ':'
+	legacySGR bool
+	// noUnderlineColor never sets the underline color, even if styled
+	// underlines are supported
+	noUnderlineColor bool
+	// sixelColors limits the number of colors in sixel palettes. Zero uses
+	// the encoder default
+	sixelColors int
+	// noGraphics uses block graphics instead of any image protocol
+	noGraphics bool
+}
+
+// quirk is an entry in the quirks table
+type quirk struct {
+	// terminal is the name of the terminal, compared ignoring case
+	terminal string
+	// before limits the quirk to versions older than before. If the
+	// terminal version is unknown, the quirk is not applied unless
+	// unversioned is set
+	before string
+	// unversioned applies the quirk when the version is unknown, for
+	// terminals which only report their version in releases newer than
+	// before
+	unversioned bool
+	quirks      quirks
+}
+
+// quirkTable are the known quirks of terminals. Every matching entry is
+// applied
+var quirkTable = []quirk{
+	{
+		// TODO: remove this when asciinema supports ':' delimiters in
+		// RGB. Asciinema also doesn't support any advanced image
+		// protocols
+		terminal: "asciinema",
+		quirks: quirks{
+			legacySGR:  true,
+			noGraphics: true,
+		},
+	},
+	{
+		// Terminal.app doesn't understand ':' delimited colors or
+		// underline colors
+		terminal: "Apple_Terminal",
+		quirks: quirks{
+			legacySGR:        true,
+			noUnderlineColor: true,
+		},
+	},
+	{
+		terminal: "screen",
+		quirks: quirks{
+			legacySGR:        true,
+			noUnderlineColor: true,
+		},
+	},
+	{
+		// tmux 3.0 added support for ':' delimiters and underline
+		// colors. tmux only answers XTVERSION since 3.3, and always
+		// reports version 0 in DA2, so we can't tell older releases
+		// apart. ';' delimiters work in all of them
+		terminal:    "tmux",
+		before:      "3.0",
+		unversioned: true,
+		quirks: quirks{
+			legacySGR:        true,
+			noUnderlineColor: true,
+		},
+	},
+	{
+		// XTerm garbles images with more colors than it has color
+		// registers. The number of registers depends on the
+		// configuration rather than the version: the numColorRegisters
+		// resource, or else the 16 registers of the VT340 XTerm must
+		// emulate to draw sixels. 16 colors is safe for every release
+		terminal: "XTerm",
+		quirks: quirks{
+			sixelColors: 16,
+		},
+	},
+}
+
+// lookupQuirks returns the quirks of the terminal
+func lookupQuirks(info TerminalInfo) quirks {
+	q := quirks{}
+	for _, entry := range quirkTable {
+		if !strings.EqualFold(entry.terminal, info.Name) {
+			continue
+		}
+		if entry.before != "" {
+			switch info.Version {
+			case "":
+				if !entry.unversioned {
+					continue
+				}
+			default:
+				if !versionLess(info.Version, entry.before) {
+					continue
+				}
+			}
+		}
+		q.legacySGR = q.legacySGR || entry.quirks.legacySGR
+		q.noUnderlineColor = q.noUnderlineColor || entry.quirks.noUnderlineColor
+		q.noGraphics = q.noGraphics || entry.quirks.noGraphics
+		if entry.quirks.sixelColors > 0 {
+			q.sixelColors = entry.quirks.sixelColors
+		}
 	}
-	if os.Getenv("VAXIS_FORCE_LEGACY_SGR") != "" {
-		fgIndexSet = strings.ReplaceAll(fgIndexSet, ":", ";")
-		fgRGBSet = strings.ReplaceAll(fgRGBSet, ":", ";")
-		bgIndexSet = strings.ReplaceAll(bgIndexSet, ":", ";")
-		bgRGBSet = strings.ReplaceAll(bgRGBSet, ":", ";")
+	return q
+}
+
+// versionLess reports if version a is older than b. Versions are compared as
+// '.' separated numbers, ignoring anything after the leading digits of each
+// part (eg "3.3a" is compared as 3.3)
+func versionLess(a string, b string) bool {
+	as := strings.Split(a, ".")
+	bs := strings.Split(b, ".")
+	for i := 0; i < len(as) || i < len(bs); i += 1 {
+		var an, bn int
+		if i < len(as) {
+			an = leadingInt(as[i])
+		}
+		if i < len(bs) {
+			bn = leadingInt(bs[i])
+		}
+		if an != bn {
+			return an < bn
+		}
+	}
+	return false
+}
+
+// leadingInt parses the leading digits of s, returning 0 if there are none
+func leadingInt(s string) int {
+	end := 0
+	for end < len(s) && s[end] >= '0' && s[end] <= '9' {
+		end += 1
 	}
+	n, _ := strconv.Atoi(s[:end])
+	return n
 }
@@ -0,0 +1,219 @@
+package vaxis
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"git.sr.ht/~rockorager/vaxis/ansi"
+)
+
+func TestLookupQuirks(t *testing.T) {
+	tests := []struct {
+		name     string
+		info     TerminalInfo
+		expected quirks
+	}{
+		{
+			name:     "asciinema",
+			info:     TerminalInfo{Name: "asciinema"},
+			expected: quirks{legacySGR: true, noGraphics: true},
+		},
+		{
+			name:     "Terminal.app",
+			info:     TerminalInfo{Name: "Apple_Terminal", Version: "453"},
+			expected: quirks{legacySGR: true, noUnderlineColor: true},
+		},
+		{
+			name:     "screen",
+			info:     TerminalInfo{Name: "screen", Version: "40900"},
+			expected: quirks{legacySGR: true, noUnderlineColor: true},
+		},
+		{
+			name:     "old tmux",
+			info:     TerminalInfo{Name: "tmux", Version: "2.9a"},
+			expected: quirks{legacySGR: true, noUnderlineColor: true},
+		},
+		{
+			name:     "tmux",
+			info:     TerminalInfo{Name: "tmux", Version: "3.3a"},
+			expected: quirks{},
+		},
+		{
+			name:     "tmux without a version",
+			info:     TerminalInfo{Name: "tmux"},
+			expected: quirks{legacySGR: true, noUnderlineColor: true},
+		},
+		{
+			name:     "XTerm",
+			info:     TerminalInfo{Name: "xterm", Version: "388"},
+			expected: quirks{sixelColors: 16},
+		},
+		{
+			name:     "kitty",
+			info:     TerminalInfo{Name: "kitty", Version: "0.31.0"},
+			expected: quirks{},
+		},
+		{
+			name:     "unknown",
+			info:     TerminalInfo{},
+			expected: quirks{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, lookupQuirks(test.info))
+		})
+	}
+}
+
+func TestVersionLess(t *testing.T) {
+	assert.True(t, versionLess("2.9a", "3.0"))
+	assert.True(t, versionLess("3", "3.0.1"))
+	assert.True(t, versionLess("0.9.10", "0.10"))
+	assert.False(t, versionLess("3.0", "3"))
+	assert.False(t, versionLess("3.3a", "3.0"))
+}
+
+func TestIdentifyTerminal(t *testing.T) {
+	tests := []struct {
+		name     string
+		reports  terminalReports
+		env      map[string]string
+		expected TerminalInfo
+	}{
+		{
+			name:     "XTVERSION with parentheses",
+			reports:  terminalReports{xtversion: "kitty(0.31.0)"},
+			expected: TerminalInfo{Name: "kitty", Version: "0.31.0"},
+		},
+		{
+			name:     "XTVERSION with a space",
+			reports:  terminalReports{xtversion: "tmux 3.3a", da2: []int{84, 0, 0}},
+			expected: TerminalInfo{Name: "tmux", Version: "3.3a"},
+		},
+		{
+			name:     "XTVERSION without a version",
+			reports:  terminalReports{xtversion: "foot"},
+			expected: TerminalInfo{Name: "foot"},
+		},
+		{
+			name:     "XTVERSION is preferred over TERM_PROGRAM",
+			reports:  terminalReports{xtversion: "WezTerm 20240203"},
+			env:      map[string]string{"TERM_PROGRAM": "tmux"},
+			expected: TerminalInfo{Name: "WezTerm", Version: "20240203"},
+		},
+		{
+			name:     "VTE",
+			reports:  terminalReports{da2: []int{65, 6802, 1}, da3: "~VTE"},
+			expected: TerminalInfo{Name: "VTE", Version: "0.68.2"},
+		},
+		{
+			name:     "DA2 xterm",
+			reports:  terminalReports{da2: []int{41, 388, 0}},
+			expected: TerminalInfo{Name: "XTerm", Version: "388"},
+		},
+		{
+			name:     "DA2 screen",
+			reports:  terminalReports{da2: []int{83, 40900, 0}},
+			expected: TerminalInfo{Name: "screen", Version: "40900"},
+		},
+		{
+			name:     "DA2 tmux",
+			reports:  terminalReports{da2: []int{84, 0, 0}},
+			expected: TerminalInfo{Name: "tmux"},
+		},
+		{
+			name:    "TERM_PROGRAM",
+			reports: terminalReports{da2: []int{1, 95, 0}},
+			env: map[string]string{
+				"TERM_PROGRAM":         "Apple_Terminal",
+				"TERM_PROGRAM_VERSION": "453",
+			},
+			expected: TerminalInfo{Name: "Apple_Terminal", Version: "453"},
+		},
+		{
+			name:     "asciinema",
+			reports:  terminalReports{xtversion: "kitty(0.31.0)"},
+			env:      map[string]string{"ASCIINEMA_REC": "1"},
+			expected: TerminalInfo{Name: "asciinema"},
+		},
+		{
+			name:     "unknown",
+			expected: TerminalInfo{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			getenv := func(key string) string {
+				return test.env[key]
+			}
+			assert.Equal(t, test.expected, identifyTerminal(test.reports, getenv))
+		})
+	}
+}
+
+func TestTerminalReports(t *testing.T) {
+	vx := &Vaxis{queue: make(chan Event, 16)}
+	input := "\x1bP>|kitty(0.31.0)\x1b\\" +
+		"\x1b[>1;4000;21c" +
+		"\x1bP!|7E565445\x1b\\"
+	parser := ansi.NewParser(strings.NewReader(input))
+	for seq := range parser.Next() {
+		if _, ok := seq.(ansi.EOF); ok {
+			break
+		}
+		vx.handleSequence(seq)
+	}
+	assert.Equal(t, xtversionReport("kitty(0.31.0)"), <-vx.queue)
+	assert.Equal(t, secondaryReport{1, 4000, 21}, <-vx.queue)
+	// VTE also reports styled underlines
+	assert.Equal(t, styledUnderlines{}, <-vx.queue)
+	assert.Equal(t, tertiaryReport("~VTE"), <-vx.queue)
+}
+
+func TestTmuxQuirks(t *testing.T) {
+	tests := []struct {
+		name     string
+		replies  string
+		expected quirks
+	}{
+		{
+			// tmux before 3.3 doesn't answer XTVERSION
+			name:     "tmux 2.9a",
+			replies:  "\x1b[>84;0;0c",
+			expected: quirks{legacySGR: true, noUnderlineColor: true},
+		},
+		{
+			name:     "tmux 3.3a",
+			replies:  "\x1bP>|tmux 3.3a\x1b\\\x1b[>84;0;0c",
+			expected: quirks{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			vx := &Vaxis{queue: make(chan Event, 16)}
+			parser := ansi.NewParser(strings.NewReader(test.replies))
+			for seq := range parser.Next() {
+				if _, ok := seq.(ansi.EOF); ok {
+					break
+				}
+				vx.handleSequence(seq)
+			}
+			reports := terminalReports{}
+			for len(vx.queue) > 0 {
+				switch ev := (<-vx.queue).(type) {
+				case xtversionReport:
+					reports.xtversion = string(ev)
+				case secondaryReport:
+					reports.da2 = ev
+				}
+			}
+			getenv := func(string) string { return "" }
+			info := identifyTerminal(reports, getenv)
+			assert.Equal(t, "tmux", info.Name)
+			assert.Equal(t, test.expected, lookupQuirks(info))
+		})
+	}
+}
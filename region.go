@@ -0,0 +1,164 @@
+package vaxis
+
+// Region is an area of the screen which receives mouse events. Regions are
+// registered with [Window.Region] while drawing a frame, and are resolved
+// against mouse events until the next frame is rendered
+type Region struct {
+	id     any
+	col    int
+	row    int
+	width  int
+	height int
+	shape  MouseShape
+}
+
+// MouseEnter is delivered when the pointer enters a region registered with
+// [Window.Region], before the [Mouse] event which entered it. It is also
+// delivered when a new frame places a region under the pointer
+type MouseEnter struct {
+	Region any
+}
+
+// MouseLeave is delivered when the pointer leaves a region registered with
+// [Window.Region], before any [MouseEnter] of the region it moved to
+type MouseLeave struct {
+	Region any
+}
+
+// Region registers the window as a region named id for the next frame, and
+// returns it. Mouse events over the region have their Region set to id, and
+// their position relative to the window. Regions registered later are on top
+// of earlier ones. Regions only last one frame, so they must be registered
+// each time the window is drawn. id must be comparable, such as a string or a
+// pointer to the widget which owns the region
+func (win Window) Region(id any) *Region {
+	col, row := win.Origin()
+	r := &Region{
+		id:     id,
+		col:    col,
+		row:    row,
+		width:  win.Width,
+		height: win.Height,
+	}
+	win.Vx.regionsNext = append(win.Vx.regionsNext, r)
+	return r
+}
+
+// clearRegions removes the regions of the next frame which are entirely within
+// the window
+func (win Window) clearRegions() {
+	col, row := win.Origin()
+	regions := win.Vx.regionsNext[:0]
+	for _, r := range win.Vx.regionsNext {
+		if r.col >= col && r.col+r.width <= col+win.Width &&
+			r.row >= row && r.row+r.height <= row+win.Height {
+			continue
+		}
+		regions = append(regions, r)
+	}
+	win.Vx.regionsNext = regions
+}
+
+// SetMouseShape sets the shape of the mouse while the pointer is over the
+// region. The shape set with [Vaxis.SetMouseShape] is restored when the
+// pointer leaves
+func (r *Region) SetMouseShape(shape MouseShape) {
+	r.shape = shape
+}
+
+// contains reports if the cell is within the region
+func (r *Region) contains(col int, row int) bool {
+	return col >= r.col && col < r.col+r.width &&
+		row >= r.row && row < r.row+r.height
+}
+
+// regionAt returns the topmost region of the last frame at the cell, if any.
+// vx.mu must be held
+func (vx *Vaxis) regionAt(col int, row int) *Region {
+	for i := len(vx.regionsLast) - 1; i >= 0; i -= 1 {
+		if vx.regionsLast[i].contains(col, row) {
+			return vx.regionsLast[i]
+		}
+	}
+	return nil
+}
+
+// findRegion returns the region of the last frame with the id, if any. vx.mu
+// must be held
+func (vx *Vaxis) findRegion(id any) *Region {
+	for i := len(vx.regionsLast) - 1; i >= 0; i -= 1 {
+		if vx.regionsLast[i].id == id {
+			return vx.regionsLast[i]
+		}
+	}
+	return nil
+}
+
+// updateHover finds the region under the pointer, and posts MouseLeave and
+// MouseEnter events if it changed. updateHover returns true if the mouse
+// shape changed. vx.mu must be held
+func (vx *Vaxis) updateHover() bool {
+	if !vx.pointer.known {
+		return false
+	}
+	prev := vx.hover
+	next := vx.regionAt(vx.pointer.col, vx.pointer.row)
+	vx.hover = next
+	switch {
+	case prev == nil && next == nil:
+		return false
+	case prev != nil && next != nil && prev.id == next.id:
+		return prev.shape != next.shape
+	}
+	if prev != nil {
+		vx.PostEvent(MouseLeave{Region: prev.id})
+	}
+	if next != nil {
+		vx.PostEvent(MouseEnter{Region: next.id})
+	}
+	return (prev != nil && prev.shape != "") || (next != nil && next.shape != "")
+}
+
+// resolveRegion sets the region of a mouse event, and updates the hovered
+// region. While a button pressed over a region is held, events are reported
+// to that region even when the pointer leaves it, so that it can be dragged
+func (vx *Vaxis) resolveRegion(mouse Mouse) Mouse {
+	vx.mu.Lock()
+	defer vx.mu.Unlock()
+	vx.pointer.col = mouse.Col
+	vx.pointer.row = mouse.Row
+	vx.pointer.known = true
+	if vx.updateHover() {
+		vx.RequestRender()
+	}
+
+	r := vx.hover
+	if vx.capture.active {
+		r = vx.findRegion(vx.capture.id)
+	}
+	wheel := mouse.Button >= MouseWheelUp && mouse.Button <= MouseWheelRight
+	switch {
+	case wheel:
+	case mouse.EventType == EventPress && !vx.capture.active && r != nil:
+		vx.capture.id = r.id
+		vx.capture.active = true
+	case mouse.EventType == EventRelease:
+		vx.capture.active = false
+	}
+	if r == nil {
+		return mouse
+	}
+	mouse.Region = r.id
+	mouse.RegionCol = mouse.Col - r.col
+	mouse.RegionRow = mouse.Row - r.row
+	return mouse
+}
+
+// mouseShape returns the shape of the mouse: the shape of the hovered region
+// if it has one, or the shape set by the application. vx.mu must be held
+func (vx *Vaxis) mouseShape() MouseShape {
+	if vx.hover != nil && vx.hover.shape != "" {
+		return vx.hover.shape
+	}
+	return vx.mouseShapeNext
+}
@@ -0,0 +1,114 @@
+package vaxis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// drain returns the events in the queue
+func drain(vx *Vaxis) []Event {
+	events := []Event{}
+	for {
+		select {
+		case ev := <-vx.queue:
+			events = append(events, ev)
+		default:
+			return events
+		}
+	}
+}
+
+func TestRegions(t *testing.T) {
+	vx := &Vaxis{queue: make(chan Event, 16)}
+	win := Window{Vx: vx, Width: 80, Height: 24}
+	win.New(10, 5, 20, 10).Region("list")
+	button := win.New(20, 8, 10, 1)
+	button.Region("button").SetMouseShape(MouseShapeClickable)
+	vx.regionsLast, vx.regionsNext = vx.regionsNext, nil
+
+	// Outside of any region
+	mouse := vx.resolveRegion(Mouse{Col: 1, Row: 1, EventType: EventMotion, Button: MouseNoButton})
+	assert.Nil(t, mouse.Region)
+	assert.Empty(t, drain(vx))
+
+	mouse = vx.resolveRegion(Mouse{Col: 12, Row: 6, EventType: EventMotion, Button: MouseNoButton})
+	assert.Equal(t, "list", mouse.Region)
+	assert.Equal(t, 2, mouse.RegionCol)
+	assert.Equal(t, 1, mouse.RegionRow)
+	assert.Equal(t, []Event{MouseEnter{Region: "list"}}, drain(vx))
+	assert.Equal(t, MouseShape(""), vx.mouseShape())
+
+	// The later region is on top, and has its own shape
+	mouse = vx.resolveRegion(Mouse{Col: 25, Row: 8, EventType: EventMotion, Button: MouseNoButton})
+	assert.Equal(t, "button", mouse.Region)
+	assert.Equal(t, 5, mouse.RegionCol)
+	assert.Equal(t, 0, mouse.RegionRow)
+	assert.Equal(t, []Event{MouseLeave{Region: "list"}, MouseEnter{Region: "button"}, Redraw{}}, drain(vx))
+	assert.Equal(t, MouseShapeClickable, vx.mouseShape())
+
+	// A press captures the mouse until the release
+	vx.resolveRegion(Mouse{Col: 25, Row: 8, EventType: EventPress})
+	mouse = vx.resolveRegion(Mouse{Col: 40, Row: 20, EventType: EventMotion})
+	assert.Equal(t, "button", mouse.Region)
+	assert.Equal(t, 20, mouse.RegionCol)
+	assert.Equal(t, 12, mouse.RegionRow)
+	mouse = vx.resolveRegion(Mouse{Col: 41, Row: 20, EventType: EventRelease})
+	assert.Equal(t, "button", mouse.Region)
+	mouse = vx.resolveRegion(Mouse{Col: 41, Row: 20, EventType: EventMotion, Button: MouseNoButton})
+	assert.Nil(t, mouse.Region)
+	vx.SetMouseShape(MouseShapeTextInput)
+	assert.Equal(t, MouseShapeTextInput, vx.mouseShape())
+
+	// A new frame without regions leaves the hovered region
+	vx.resolveRegion(Mouse{Col: 12, Row: 6, EventType: EventMotion, Button: MouseNoButton})
+	drain(vx)
+	vx.mu.Lock()
+	vx.regionsLast = nil
+	vx.updateHover()
+	vx.mu.Unlock()
+	assert.Equal(t, []Event{MouseLeave{Region: "list"}}, drain(vx))
+}
+
+func TestRegionsClear(t *testing.T) {
+	vx, _ := newTestVaxis(80, 24)
+	vx.queue = make(chan Event, 16)
+	vx.resolveRegion(Mouse{Col: 1, Row: 1, EventType: EventMotion, Button: MouseNoButton})
+
+	// Draw twice before rendering: only the regions of the last draw are
+	// used
+	win := vx.Window()
+	win.Clear()
+	win.New(0, 0, 10, 10).Region("stale")
+	win.Clear()
+	win.New(20, 0, 10, 10).Region("list")
+	vx.Render()
+	assert.Empty(t, drain(vx))
+	mouse := vx.resolveRegion(Mouse{Col: 1, Row: 1, EventType: EventMotion, Button: MouseNoButton})
+	assert.Nil(t, mouse.Region)
+	mouse = vx.resolveRegion(Mouse{Col: 21, Row: 1, EventType: EventMotion, Button: MouseNoButton})
+	assert.Equal(t, "list", mouse.Region)
+}
+
+func TestRegionsClearChild(t *testing.T) {
+	vx, _ := newTestVaxis(80, 24)
+	vx.queue = make(chan Event, 16)
+
+	// Widgets such as buttons clear their own window when drawn, which
+	// must not remove the regions of their siblings
+	win := vx.Window()
+	win.Clear()
+	win.New(0, 0, 10, 1).Region("sibling")
+	child := win.New(20, 0, 10, 1)
+	child.New(2, 0, 4, 1).Region("stale")
+	child.Clear()
+	child.Region("child")
+	vx.Render()
+	mouse := vx.resolveRegion(Mouse{Col: 1, Row: 0, EventType: EventMotion, Button: MouseNoButton})
+	assert.Equal(t, "sibling", mouse.Region)
+	mouse = vx.resolveRegion(Mouse{Col: 23, Row: 0, EventType: EventMotion, Button: MouseNoButton})
+	assert.Equal(t, "child", mouse.Region)
+	vx.mu.Lock()
+	defer vx.mu.Unlock()
+	assert.Nil(t, vx.findRegion("stale"))
+}
@@ -0,0 +1,209 @@
+package vaxis
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderBytes(t *testing.T) {
+	tests := []struct {
+		name     string
+		cols     int
+		rows     int
+		caps     capabilities
+		quirks   quirks
+		depth    ColorDepth
+		last     []Segment
+		next     []Segment
+		expected string
+	}{
+		{
+			name: "merged SGR",
+			cols: 10,
+			rows: 1,
+			next: []Segment{{
+				Text: "ab",
+				Style: Style{
+					Foreground: IndexColor(1),
+					Background: IndexColor(2),
+					Attribute:  AttrBold,
+				},
+			}},
+			expected: "\x1b[1;1H\x1b[31;42;1mab\x1b[m",
+		},
+		{
+			name: "subparameters in their own sequence",
+			cols: 10,
+			rows: 1,
+			caps: capabilities{rgb: true},
+			next: []Segment{{
+				Text: "a",
+				Style: Style{
+					Foreground: RGBColor(1, 2, 3),
+					Background: IndexColor(9),
+					Attribute:  AttrItalic,
+				},
+			}},
+			expected: "\x1b[1;1H\x1b[38:2:1:2:3m\x1b[101;3ma\x1b[m",
+		},
+		{
+			name:   "legacy SGR",
+			cols:   10,
+			rows:   1,
+			caps:   capabilities{rgb: true, styledUnderlines: true},
+			quirks: quirks{legacySGR: true},
+			next: []Segment{{
+				Text: "a",
+				Style: Style{
+					Foreground:     RGBColor(1, 2, 3),
+					UnderlineColor: IndexColor(200),
+					UnderlineStyle: UnderlineCurly,
+				},
+			}},
+			expected: "\x1b[1;1H\x1b[4:3m\x1b[38;2;1;2;3;58;5;200ma\x1b[m",
+		},
+		{
+			name:   "no underline color",
+			cols:   10,
+			rows:   1,
+			caps:   capabilities{styledUnderlines: true},
+			quirks: quirks{noUnderlineColor: true},
+			next: []Segment{{
+				Text: "a",
+				Style: Style{
+					UnderlineColor: IndexColor(200),
+					UnderlineStyle: UnderlineCurly,
+				},
+			}},
+			expected: "\x1b[1;1H\x1b[4:3ma\x1b[m",
+		},
+		{
+			name:  "16 colors",
+			cols:  10,
+			rows:  1,
+			depth: ColorDepth16,
+			next: []Segment{{
+				Text: "a",
+				Style: Style{
+					Foreground: RGBColor(0xff, 0x10, 0x10),
+					Background: IndexColor(21),
+				},
+			}},
+			expected: "\x1b[1;1H\x1b[91;44ma\x1b[m",
+		},
+		{
+			name:  "monochrome",
+			cols:  10,
+			rows:  1,
+			depth: ColorDepthMonochrome,
+			next: []Segment{{
+				Text: "a",
+				Style: Style{
+					Foreground: RGBColor(0xff, 0x10, 0x10),
+					Background: IndexColor(4),
+					Attribute:  AttrReverse,
+				},
+			}},
+			expected: "\x1b[1;1H\x1b[7ma\x1b[m",
+		},
+		{
+			name:  "monochrome background is reversed",
+			cols:  10,
+			rows:  1,
+			depth: ColorDepthMonochrome,
+			next: []Segment{
+				{Text: "a", Style: Style{Foreground: IndexColor(1)}},
+				{Text: "b", Style: Style{Background: RGBColor(0x20, 0x20, 0x80)}},
+				{Text: "c", Style: Style{Background: IndexColor(4), Attribute: AttrBold}},
+				{Text: "d"},
+			},
+			expected: "\x1b[1;1Ha\x1b[7mb\x1b[1mc\x1b[22;27md\x1b[m",
+		},
+		{
+			name:     "erase to end of line",
+			cols:     20,
+			rows:     1,
+			last:     []Segment{{Text: "hello world"}},
+			next:     []Segment{{Text: "hi"}},
+			expected: "\x1b[1;2Hi\x1b[K\x1b[m",
+		},
+		{
+			name:     "erase characters",
+			cols:     30,
+			rows:     1,
+			last:     []Segment{{Text: strings.Repeat("x", 20) + " end"}},
+			next:     []Segment{{Text: strings.Repeat(" ", 21) + "end"}},
+			expected: "\x1b[1;1H\x1b[21X\x1b[m",
+		},
+		{
+			name:     "short blank run is printed",
+			cols:     10,
+			rows:     1,
+			last:     []Segment{{Text: "ab cd"}},
+			next:     []Segment{{Text: "ab  d"}},
+			expected: "\x1b[1;4H \x1b[m",
+		},
+		{
+			name:     "repeat character",
+			cols:     30,
+			rows:     1,
+			caps:     capabilities{repeatCharacter: true},
+			next:     []Segment{{Text: strings.Repeat("=", 20)}},
+			expected: "\x1b[1;1H=\x1b[19b\x1b[m",
+		},
+		{
+			name:     "repeat unsupported",
+			cols:     30,
+			rows:     1,
+			next:     []Segment{{Text: strings.Repeat("=", 20)}},
+			expected: "\x1b[1;1H" + strings.Repeat("=", 20) + "\x1b[m",
+		},
+		{
+			name:     "short repeat is printed",
+			cols:     30,
+			rows:     1,
+			caps:     capabilities{repeatCharacter: true},
+			next:     []Segment{{Text: "==="}},
+			expected: "\x1b[1;1H===\x1b[m",
+		},
+		{
+			name:     "repeat multiple codepoints",
+			cols:     30,
+			rows:     1,
+			caps:     capabilities{repeatCharacter: true},
+			next:     []Segment{{Text: strings.Repeat("é", 10)}},
+			expected: "\x1b[1;1H" + strings.Repeat("é", 10) + "\x1b[m",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			vx, out := newTestVaxis(test.cols, test.rows)
+			vx.caps = test.caps
+			vx.quirks = test.quirks
+			vx.colorDepth = test.depth
+			vx.Window().Clear()
+			vx.Window().Println(0, test.last...)
+			vx.Render()
+			out.Reset()
+
+			win := vx.Window()
+			win.Clear()
+			win.Println(0, test.next...)
+			vx.Render()
+			assert.Equal(t, test.expected, out.String())
+		})
+	}
+}
+
+func TestRenderEraseWrapPending(t *testing.T) {
+	vx, out := newTestVaxis(10, 2)
+	renderLines(vx, []string{strings.Repeat("a", 10), strings.Repeat("b", 10)})
+	out.Reset()
+
+	// After printing the last column of the first row the terminal has a
+	// wrap pending. We must move to the second row before erasing it
+	renderLines(vx, []string{strings.Repeat("c", 10)})
+	assert.Equal(t, "\x1b[1;1Hcccccccccc\x1b[2;1H\x1b[K\x1b[m", out.String())
+}
@@ -0,0 +1,138 @@
+package vaxis
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sync"
+)
+
+// Model is an application, or a part of one, which can be run with [Run]. The
+// built in widgets share the same shape
+type Model interface {
+	// Update is called from the event loop with each event
+	Update(ev Event)
+	// Draw is called from the event loop whenever a render is needed. The
+	// window has already been cleared
+	Draw(win Window)
+}
+
+// Initializer may be implemented by a [Model] which needs the [Vaxis]
+// instance it is running on. Init is called from the event loop before any
+// events are delivered
+type Initializer interface {
+	Init(vx *Vaxis)
+}
+
+// PanicError is returned from [Run] when the [Model] panics. The terminal is
+// restored before Run returns
+type PanicError struct {
+	// Value is the value passed to panic
+	Value any
+	// Stack is the stack trace of the goroutine which panicked
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("vaxis: panic: %v\n%s", e.Value, e.Stack)
+}
+
+// runState holds the state of a running event loop
+type runState struct {
+	cancel context.CancelFunc
+	once   sync.Once
+	err    error
+	quit   bool
+}
+
+// Run creates a new [Vaxis] with opts and runs the model until ctx is
+// canceled, [Vaxis.Quit] is called, or Vaxis is closed. See [Vaxis.Run]
+func Run(ctx context.Context, opts Options, m Model) error {
+	vx, err := New(opts)
+	if err != nil {
+		return err
+	}
+	return vx.Run(ctx, m)
+}
+
+// Run runs the event loop for the model. Run owns the loop: SyncFuncs are
+// called and a render is requested, and every other event is passed to the
+// model's Update method and also requests a render. Renders are coalesced
+// according to Options.MaxFPS, and the model is drawn once for each Redraw
+// event.
+//
+// Run returns when ctx is canceled, when [Vaxis.Quit] is called, or when Vaxis
+// is closed. If the model panics, the panic is recovered and returned as a
+// [*PanicError]. In all cases Vaxis is closed and the terminal is restored
+// before Run returns
+func (vx *Vaxis) Run(ctx context.Context, m Model) (err error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	state := &runState{cancel: cancel}
+	vx.mu.Lock()
+	vx.run = state
+	vx.mu.Unlock()
+
+	defer func() {
+		if p := recover(); p != nil {
+			err = &PanicError{
+				Value: p,
+				Stack: debug.Stack(),
+			}
+		}
+		vx.Close()
+	}()
+
+	if init, ok := m.(Initializer); ok {
+		init.Init(vx)
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			if state.quit {
+				return state.err
+			}
+			return ctx.Err()
+		case ev := <-vx.events:
+			switch ev := ev.(type) {
+			case QuitEvent:
+				return nil
+			case SyncFunc:
+				ev()
+				vx.RequestRender()
+			case Redraw:
+				m.Update(ev)
+				vx.draw(m)
+			default:
+				m.Update(ev)
+				vx.RequestRender()
+			}
+		}
+	}
+}
+
+// draw clears the window, draws the model and renders it
+func (vx *Vaxis) draw(m Model) {
+	vx.HideCursor()
+	win := vx.Window()
+	win.Clear()
+	m.Draw(win)
+	vx.Render()
+}
+
+// Quit ends the event loop started with [Run]. err is returned from Run. Quit
+// is safe to call from any goroutine, only the first call has any effect. Quit
+// does nothing if the event loop isn't running
+func (vx *Vaxis) Quit(err error) {
+	vx.mu.Lock()
+	state := vx.run
+	vx.mu.Unlock()
+	if state == nil {
+		return
+	}
+	state.once.Do(func() {
+		state.err = err
+		state.quit = true
+		state.cancel()
+	})
+}
@@ -0,0 +1,145 @@
+package vaxis_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"git.sr.ht/~rockorager/vaxis"
+	"git.sr.ht/~rockorager/vaxis/vaxistest"
+)
+
+// counter counts key presses, and panics on "p"
+type counter struct {
+	vx      *vaxis.Vaxis
+	keys    int
+	draws   int
+	message string
+}
+
+func (c *counter) Init(vx *vaxis.Vaxis) {
+	c.vx = vx
+}
+
+func (c *counter) Update(ev vaxis.Event) {
+	switch ev := ev.(type) {
+	case vaxis.Key:
+		switch ev.String() {
+		case "p":
+			panic("boom")
+		case "q":
+			c.vx.Quit(errors.New("quit"))
+		}
+		c.keys += 1
+	}
+}
+
+func (c *counter) Draw(win vaxis.Window) {
+	c.draws += 1
+	win.Println(0, vaxis.Segment{Text: fmt.Sprintf("keys: %d", c.keys)})
+	win.Println(1, vaxis.Segment{Text: c.message})
+}
+
+// startRun runs the model on a new emulated terminal, returning a channel
+// which receives the result of Run
+func startRun(t *testing.T, ctx context.Context, m vaxis.Model) (*vaxistest.Terminal, chan error) {
+	t.Helper()
+	tt, err := vaxistest.New(20, 4, vaxis.Options{})
+	require.NoError(t, err)
+	t.Cleanup(tt.Close)
+	result := make(chan error, 1)
+	go func() {
+		result <- tt.Vx.Run(ctx, m)
+	}()
+	return tt, result
+}
+
+// waitRow waits for the row of the emulated terminal to have the given content
+func waitRow(t *testing.T, tt *vaxistest.Terminal, row int, expected string) {
+	t.Helper()
+	assert.Eventually(t, func() bool {
+		_ = tt.Sync()
+		return trimRows(tt.Row(row)) == expected
+	}, time.Second, 10*time.Millisecond, "expected row %d to be %q, got %q", row, expected, tt.Row(row))
+}
+
+func waitResult(t *testing.T, result chan error) error {
+	t.Helper()
+	select {
+	case err := <-result:
+		return err
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return")
+		return nil
+	}
+}
+
+func TestRun(t *testing.T) {
+	m := &counter{}
+	tt, result := startRun(t, context.Background(), m)
+
+	waitRow(t, tt, 0, "keys: 0")
+	tt.SendKey(vaxis.Key{Keycode: 'j', Text: "j"})
+	tt.SendKey(vaxis.Key{Keycode: 'k', Text: "k"})
+	waitRow(t, tt, 0, "keys: 2")
+
+	// SyncFuncs are called from the loop, and trigger a render
+	tt.Vx.SyncFunc(func() {
+		m.message = "synced"
+	})
+	waitRow(t, tt, 1, "synced")
+
+	tt.SendKey(vaxis.Key{Keycode: 'q', Text: "q"})
+	assert.EqualError(t, waitResult(t, result), "quit")
+}
+
+func TestRunCoalescesRenders(t *testing.T) {
+	m := &counter{}
+	tt, result := startRun(t, context.Background(), m)
+	waitRow(t, tt, 0, "keys: 0")
+
+	done := make(chan int)
+	tt.Vx.SyncFunc(func() {
+		draws := m.draws
+		for i := 0; i < 50; i += 1 {
+			tt.Vx.PostEvent(vaxis.Key{Keycode: 'j', Text: "j"})
+		}
+		tt.Vx.SyncFunc(func() {
+			done <- draws
+		})
+	})
+	before := <-done
+	waitRow(t, tt, 0, "keys: 50")
+	tt.Vx.SyncFunc(func() {
+		done <- m.draws
+	})
+	after := <-done
+	assert.Less(t, after-before, 50)
+
+	tt.Vx.Quit(nil)
+	assert.NoError(t, waitResult(t, result))
+}
+
+func TestRunContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	tt, result := startRun(t, ctx, &counter{})
+	waitRow(t, tt, 0, "keys: 0")
+	cancel()
+	assert.ErrorIs(t, waitResult(t, result), context.Canceled)
+}
+
+func TestRunPanic(t *testing.T) {
+	tt, result := startRun(t, context.Background(), &counter{})
+	waitRow(t, tt, 0, "keys: 0")
+	tt.SendKey(vaxis.Key{Keycode: 'p', Text: "p"})
+	err := waitResult(t, result)
+	var perr *vaxis.PanicError
+	require.ErrorAs(t, err, &perr)
+	assert.Equal(t, "boom", perr.Value)
+	assert.Contains(t, string(perr.Stack), "counter")
+}
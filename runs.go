@@ -0,0 +1,152 @@
+package vaxis
+
+import (
+	"unicode/utf8"
+)
+
+// isBlank returns true if the cell is an empty cell with the default style,
+// which is what the terminal leaves behind when erasing
+func isBlank(cell Cell) bool {
+	if cell.Style != (Style{}) || cell.sixel {
+		return false
+	}
+	switch cell.Grapheme {
+	case "", " ":
+		return cell.Width <= 1
+	}
+	return false
+}
+
+// changedRun returns the number of consecutive cells, starting at col, for
+// which match returns true. changed is the number of those cells which differ
+// from the last frame, ie the number of cells we would otherwise print
+func (vx *Vaxis) changedRun(row int, col int, match func(Cell) bool) (n int, changed int) {
+	next := vx.screenNext.buf[row]
+	last := vx.screenLast.buf[row]
+	for c := col; c < len(next); c += 1 {
+		if !match(next[c]) {
+			break
+		}
+		n += 1
+		if vx.refresh || next[c] != last[c] {
+			changed += 1
+		}
+	}
+	return n, changed
+}
+
+// eraseRun erases the run of blank cells starting at col with ECH, or EL when
+// the run reaches the end of the row. The cursor must already be at col, with
+// the default style set. The number of cells erased is returned, which is 0 if
+// printing the cells would be cheaper. The cursor is not moved by either
+// sequence, so the caller must reposition before printing again
+func (vx *Vaxis) eraseRun(row int, col int, wrapPending bool) int {
+	if !isBlank(vx.screenNext.buf[row][col]) {
+		return 0
+	}
+	// The cell at col is always changed, it's why we are printing
+	n, changed := vx.changedRun(row, col+1, isBlank)
+	n += 1
+	changed += 1
+	if n < 2 {
+		return 0
+	}
+	seq := eraseLine
+	cost := len(eraseLine)
+	if col+n < len(vx.screenNext.buf[row]) {
+		if !vx.canEraseChars() {
+			return 0
+		}
+		seq = tparm(ech, n)
+		cost = len(seq) + len(tparm(cup, row+1, col+n+1))
+	}
+	if wrapPending {
+		// ECH and EL don't resolve a pending wrap, so we have to move
+		// to the start of the row ourselves
+		cost += len(tparm(cup, row+1, col+1))
+	}
+	if changed <= cost {
+		return 0
+	}
+	if wrapPending {
+		_, _ = vx.tw.WriteString(vx.moveCursor(row, col))
+	}
+	_, _ = vx.tw.WriteString(seq)
+	copy(vx.screenLast.buf[row][col:col+n], vx.screenNext.buf[row][col:col+n])
+	return n
+}
+
+// canEraseChars reports if we can use ECH. ECH is part of ECMA-48, so we assume
+// it is supported unless the terminfo entry says otherwise
+func (vx *Vaxis) canEraseChars() bool {
+	if vx.terminfo == nil {
+		return true
+	}
+	_, ok := vx.terminfo.strings["ech"]
+	return ok
+}
+
+// repeatRun repeats the just printed cell at col with REP for as long as the
+// following cells are identical to it. The number of additional cells written
+// is returned, which is 0 if printing them would be cheaper or if the terminal
+// doesn't support REP. REP repeats the last codepoint printed, so only single
+// codepoint, single width characters are repeated
+func (vx *Vaxis) repeatRun(row int, col int, printed Cell) int {
+	if !vx.caps.repeatCharacter {
+		return 0
+	}
+	if printed.Width != 1 || utf8.RuneCountInString(printed.Grapheme) != 1 {
+		return 0
+	}
+	cell := vx.screenNext.buf[row][col]
+	if col+1 >= len(vx.screenNext.buf[row]) {
+		return 0
+	}
+	n, changed := vx.changedRun(row, col+1, func(c Cell) bool {
+		return c == cell
+	})
+	if n == 0 {
+		return 0
+	}
+	seq := tparm(rep, n)
+	if changed*len(printed.Grapheme) <= len(seq) {
+		return 0
+	}
+	_, _ = vx.tw.WriteString(seq)
+	copy(vx.screenLast.buf[row][col+1:col+1+n], vx.screenNext.buf[row][col+1:col+1+n])
+	for i := 0; i < n; i += 1 {
+		vx.advanceInline(1)
+	}
+	return n
+}
+
+// printGap reports if the run of unchanged cells starting at col should be
+// printed instead of moving the cursor over it. This is the case when the
+// run is followed by a changed cell on the same row, and printing the cells
+// is cheaper than the cursor movement, such as for the spaces between words.
+// The cursor must be at col, with style set
+func (vx *Vaxis) printGap(row int, col int, style Style) bool {
+	next := vx.screenNext.buf[row]
+	last := vx.screenLast.buf[row]
+	cost := 0
+	for c := col; c < len(next); c += 1 {
+		if next[c] != last[c] {
+			return cost < len(vx.moveCursor(row, c))
+		}
+		if next[c].Style != style || next[c].sixel {
+			return false
+		}
+		switch {
+		case next[c].Grapheme == "":
+			cost += 1
+		case next[c].Width == 1:
+			cost += len(next[c].Grapheme)
+		default:
+			return false
+		}
+		if cost >= len(tparm(cup, row+1, c+1)) {
+			return false
+		}
+	}
+	return false
+}
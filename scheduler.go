@@ -0,0 +1,83 @@
+package vaxis
+
+import (
+	"sync"
+	"time"
+)
+
+// defaultMaxFPS is the frame rate used when Options.MaxFPS is zero
+const defaultMaxFPS = 60
+
+// scheduler coalesces render requests so that at most one Redraw event is
+// posted per frame interval
+type scheduler struct {
+	mu sync.Mutex
+	// interval is the minimum time between two Redraw events
+	interval time.Duration
+	// last is the time the last Redraw event was posted
+	last time.Time
+	// pending is true while a Redraw is waiting to be posted
+	pending bool
+	timer   *time.Timer
+	stopped bool
+}
+
+// frameInterval returns the frame interval for the given maximum frame rate.
+// Negative values disable the limit
+func frameInterval(maxFPS int) time.Duration {
+	switch {
+	case maxFPS == 0:
+		maxFPS = defaultMaxFPS
+	case maxFPS < 0:
+		return 0
+	}
+	return time.Second / time.Duration(maxFPS)
+}
+
+// RequestRender asks for a Redraw event to be posted to the event queue.
+// Requests are coalesced: no matter how many requests are made, at most one
+// Redraw is posted per frame interval (see Options.MaxFPS). A request made
+// while one is pending is folded into the pending Redraw. RequestRender is safe
+// to call from any goroutine
+func (vx *Vaxis) RequestRender() {
+	s := &vx.scheduler
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.pending || s.stopped {
+		return
+	}
+	s.pending = true
+	wait := s.interval - time.Since(s.last)
+	if wait <= 0 {
+		vx.postRender()
+		return
+	}
+	s.timer = time.AfterFunc(wait, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if !s.pending || s.stopped {
+			return
+		}
+		vx.postRender()
+	})
+}
+
+// postRender posts the pending Redraw. The scheduler must be locked
+func (vx *Vaxis) postRender() {
+	s := &vx.scheduler
+	s.pending = false
+	s.last = time.Now()
+	vx.PostEvent(Redraw{})
+}
+
+// stopScheduler cancels any pending Redraw. No more will be posted
+func (vx *Vaxis) stopScheduler() {
+	s := &vx.scheduler
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stopped = true
+	s.pending = false
+	if s.timer != nil {
+		s.timer.Stop()
+	}
+}
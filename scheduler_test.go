@@ -0,0 +1,71 @@
+package vaxis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// countRedraws drains the queue for the duration, returning the number of
+// Redraw events received
+func countRedraws(vx *Vaxis, d time.Duration) int {
+	n := 0
+	timeout := time.After(d)
+	for {
+		select {
+		case ev := <-vx.queue:
+			if _, ok := ev.(Redraw); ok {
+				n += 1
+			}
+		case <-timeout:
+			return n
+		}
+	}
+}
+
+func TestFrameInterval(t *testing.T) {
+	assert.Equal(t, time.Second/60, frameInterval(0))
+	assert.Equal(t, time.Second/30, frameInterval(30))
+	assert.Equal(t, time.Duration(0), frameInterval(-1))
+}
+
+func TestRequestRender(t *testing.T) {
+	vx := &Vaxis{queue: make(chan Event, 1024)}
+	vx.scheduler.interval = 50 * time.Millisecond
+
+	// The first request is delivered immediately, the rest within the
+	// same frame collapse into a single Redraw
+	for i := 0; i < 100; i += 1 {
+		vx.RequestRender()
+	}
+	assert.Equal(t, 2, countRedraws(vx, 80*time.Millisecond))
+
+	// Nothing is delivered without a request
+	assert.Equal(t, 0, countRedraws(vx, 80*time.Millisecond))
+
+	vx.RequestRender()
+	assert.Equal(t, 1, countRedraws(vx, 20*time.Millisecond))
+}
+
+func TestRequestRenderStopped(t *testing.T) {
+	vx := &Vaxis{queue: make(chan Event, 1024)}
+	vx.scheduler.interval = 50 * time.Millisecond
+
+	vx.RequestRender()
+	vx.RequestRender()
+	vx.stopScheduler()
+	assert.Equal(t, 1, countRedraws(vx, 80*time.Millisecond))
+	vx.RequestRender()
+	assert.Equal(t, 0, countRedraws(vx, 20*time.Millisecond))
+}
+
+func TestRequestRenderUnlimited(t *testing.T) {
+	vx := &Vaxis{queue: make(chan Event, 1024)}
+	vx.scheduler.interval = frameInterval(-1)
+
+	for i := 0; i < 10; i += 1 {
+		vx.RequestRender()
+	}
+	assert.Equal(t, 10, countRedraws(vx, 20*time.Millisecond))
+}
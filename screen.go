@@ -4,6 +4,14 @@ type screen struct {
 	buf  [][]Cell
 	rows int
 	cols int
+	// dirty marks the rows which have been written to since their hash was
+	// last computed
+	dirty []bool
+	// hashes holds a hash of the content of each row. For the next screen,
+	// these are updated from the dirty rows before each render. For the last
+	// screen, they are the hash of the content last rendered to each row,
+	// or 0 if we don't know what the row contains
+	hashes []uint64
 }
 
 func newScreen() *screen {
@@ -21,6 +29,11 @@ func (s *screen) resize(cols int, rows int) {
 	for row := range s.buf {
 		s.buf[row] = make([]Cell, cols)
 	}
+	s.dirty = make([]bool, rows)
+	for row := range s.dirty {
+		s.dirty[row] = true
+	}
+	s.hashes = make([]uint64, rows)
 	s.rows = rows
 	s.cols = cols
 }
@@ -37,6 +50,7 @@ func (s *screen) setCell(col int, row int, text Cell) {
 		return
 	}
 	s.buf[row][col] = text
+	s.dirty[row] = true
 }
 
 func (s *screen) setStyle(col int, row int, style Style) {
@@ -50,4 +64,5 @@ func (s *screen) setStyle(col int, row int, style Style) {
 		return
 	}
 	s.buf[row][col].Style = style
+	s.dirty[row] = true
 }
@@ -0,0 +1,118 @@
+package vaxis
+
+import (
+	"io"
+)
+
+// scroll describes a block of rows which has moved vertically between two
+// frames. top and bottom are the inclusive bounds of the scrolling region. n is
+// the number of rows the content moved: positive values moved up (SU),
+// negative values moved down (SD)
+type scroll struct {
+	top    int
+	bottom int
+	n      int
+}
+
+// detectScroll compares the row hashes of the last and next screens and
+// returns the vertical shift which would allow the most rows to be reused. ok
+// is false if no shift is worth emitting. The hashes of the next screen must be
+// up to date
+func (vx *Vaxis) detectScroll() (scroll, bool) {
+	last := vx.screenLast
+	next := vx.screenNext
+	if last.rows != next.rows || last.cols != next.cols {
+		return scroll{}, false
+	}
+	rows := next.rows
+	lastHash := last.hashes
+	nextHash := next.hashes
+
+	// Narrow the region down to the rows which changed
+	top := 0
+	for top < rows && lastHash[top] == nextHash[top] {
+		top += 1
+	}
+	bottom := rows - 1
+	for bottom > top && lastHash[bottom] == nextHash[bottom] {
+		bottom -= 1
+	}
+	height := bottom - top + 1
+	if height < 3 {
+		return scroll{}, false
+	}
+
+	best := scroll{}
+	bestCount := 0
+	for n := 1; n < height; n += 1 {
+		for _, dir := range []int{n, -n} {
+			count := 0
+			for row := top; row <= bottom; row += 1 {
+				src := row + dir
+				if src < top || src > bottom {
+					continue
+				}
+				if lastHash[src] != 0 && nextHash[row] == lastHash[src] {
+					count += 1
+				}
+			}
+			if count > bestCount {
+				bestCount = count
+				best = scroll{top: top, bottom: bottom, n: dir}
+			}
+		}
+		if bestCount >= height-n-1 {
+			// We can't do any better with a larger shift
+			break
+		}
+	}
+	// Only scroll if we reuse at least half of the region. Otherwise, the
+	// scroll costs more than it saves
+	if bestCount*2 < height {
+		return scroll{}, false
+	}
+	return best, true
+}
+
+// applyScroll writes the sequences to scroll the region, and updates the last
+// screen to match what the terminal now displays
+func (vx *Vaxis) applyScroll(w io.StringWriter, s scroll) {
+	_, _ = w.WriteString(tparm(decstbm, s.top+1, s.bottom+1))
+	switch {
+	case s.n > 0:
+		_, _ = w.WriteString(tparm(scrollUp, s.n))
+	case s.n < 0:
+		_, _ = w.WriteString(tparm(scrollDown, -s.n))
+	}
+	_, _ = w.WriteString(decstbmReset)
+
+	buf := vx.screenLast.buf
+	hashes := vx.screenLast.hashes
+	blank := Cell{Character: Character{Grapheme: " ", Width: 1}}
+	switch {
+	case s.n > 0:
+		for row := s.top; row <= s.bottom; row += 1 {
+			if row+s.n > s.bottom {
+				for col := range buf[row] {
+					buf[row][col] = blank
+				}
+				hashes[row] = 0
+				continue
+			}
+			copy(buf[row], buf[row+s.n])
+			hashes[row] = hashes[row+s.n]
+		}
+	case s.n < 0:
+		for row := s.bottom; row >= s.top; row -= 1 {
+			if row+s.n < s.top {
+				for col := range buf[row] {
+					buf[row][col] = blank
+				}
+				hashes[row] = 0
+				continue
+			}
+			copy(buf[row], buf[row+s.n])
+			hashes[row] = hashes[row+s.n]
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package vaxis
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestVaxis returns a Vaxis which renders into the returned buffer
+func newTestVaxis(cols int, rows int) (*Vaxis, *bytes.Buffer) {
+	out := bytes.NewBuffer(nil)
+	vx := &Vaxis{
+		screenNext: newScreen(),
+		screenLast: newScreen(),
+		charCache:  make(map[string]int),
+	}
+	vx.tw = &writer{
+		buf: bytes.NewBuffer(nil),
+		w:   out,
+		vx:  vx,
+	}
+	vx.screenNext.resize(cols, rows)
+	vx.screenLast.resize(cols, rows)
+	vx.winSize = Resize{Cols: cols, Rows: rows}
+	return vx, out
+}
+
+// renderLines clears the window, prints each line on its own row, and renders
+func renderLines(vx *Vaxis, lines []string) {
+	win := vx.Window()
+	win.Clear()
+	for i, line := range lines {
+		win.Println(i, Segment{Text: line})
+	}
+	vx.Render()
+}
+
+func logLines(start int, n int) []string {
+	lines := make([]string, 0, n)
+	for i := start; i < start+n; i += 1 {
+		lines = append(lines, fmt.Sprintf("log line %d", i))
+	}
+	return lines
+}
+
+func TestDetectScroll(t *testing.T) {
+	tests := []struct {
+		name     string
+		last     []string
+		next     []string
+		expected scroll
+		ok       bool
+	}{
+		{
+			name:     "scroll up one",
+			last:     logLines(0, 10),
+			next:     logLines(1, 10),
+			expected: scroll{top: 0, bottom: 9, n: 1},
+			ok:       true,
+		},
+		{
+			name:     "scroll up three",
+			last:     logLines(0, 10),
+			next:     logLines(3, 10),
+			expected: scroll{top: 0, bottom: 9, n: 3},
+			ok:       true,
+		},
+		{
+			name:     "scroll down two",
+			last:     logLines(2, 10),
+			next:     logLines(0, 10),
+			expected: scroll{top: 0, bottom: 9, n: -2},
+			ok:       true,
+		},
+		{
+			name:     "scroll region with fixed header and footer",
+			last:     append(append([]string{"header"}, logLines(0, 8)...), "footer"),
+			next:     append(append([]string{"header"}, logLines(1, 8)...), "footer"),
+			expected: scroll{top: 1, bottom: 8, n: 1},
+			ok:       true,
+		},
+		{
+			name: "no scroll",
+			last: logLines(0, 10),
+			next: logLines(0, 10),
+		},
+		{
+			name: "unrelated content",
+			last: logLines(0, 10),
+			next: logLines(100, 10),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			vx, _ := newTestVaxis(20, 10)
+			renderLines(vx, test.last)
+			win := vx.Window()
+			win.Clear()
+			for i, line := range test.next {
+				win.Println(i, Segment{Text: line})
+			}
+			vx.hashRows(vx.screenNext)
+			actual, ok := vx.detectScroll()
+			assert.Equal(t, test.ok, ok)
+			if test.ok {
+				assert.Equal(t, test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestRenderScroll(t *testing.T) {
+	vx, out := newTestVaxis(20, 10)
+	renderLines(vx, logLines(0, 10))
+	full := out.Len()
+	out.Reset()
+
+	renderLines(vx, logLines(1, 10))
+	actual := out.String()
+	assert.True(t, strings.HasPrefix(actual, "\x1b[1;10r\x1b[1S\x1b[r"), "expected a scroll, got %q", actual)
+	// Only the last row should be drawn
+	assert.Equal(t, 1, strings.Count(actual, "log"))
+	assert.Contains(t, actual, "\x1b[10;1Hlog line 10")
+	assert.Less(t, out.Len(), full/4)
+
+	// The last screen must match what the terminal displays
+	for row, line := range logLines(1, 10) {
+		for col, char := range Characters(line) {
+			assert.Equal(t, char.Grapheme, vx.screenLast.buf[row][col].Grapheme)
+		}
+	}
+}
@@ -11,14 +11,15 @@ const (
 	// Generic DSR
 	dsr = "\x1b[?%dn"
 	// Device primary attributes
-	primaryAttributes  = "\x1b[c"
-	tertiaryAttributes = "\x1b[=c"
+	primaryAttributes   = "\x1b[c"
+	secondaryAttributes = "\x1b[>c"
+	tertiaryAttributes  = "\x1b[=c"
 	// Device Status Report - XTVERSION
 	xtversion = "\x1b[>0q"
 	// kitty keyboard protocol
-	kittyKBQuery  = "\x1b[?u"
-	kittyKBEnable = "\x1b[>%du"
-	kittyKBPop    = "\x1b[<u"
+	kittyKBQuery = "\x1b[?u"
+	kittyKBPush  = "\x1b[>%du"
+	kittyKBPop   = "\x1b[<%du"
 	// kitty graphics protocol
 	kittyGquery = "\x1b_Gi=1,a=q\x1b\\"
 	// sixel query XTSMGRAPHICS
@@ -27,6 +28,19 @@ const (
 	// Misc
 	clear        = "\x1b[H\x1b[2J"
 	cup          = "\x1B[%d;%dH"
+	cuu          = "\x1b[%dA"
+	cud          = "\x1b[%dB"
+	cuf          = "\x1b[%dC"
+	eraseBelow   = "\x1b[J"
+	eraseLine    = "\x1b[K"
+	ech          = "\x1b[%dX"
+	rep          = "\x1b[%db"
+	decsc        = "\x1b7"
+	decstbm      = "\x1b[%d;%dr"
+	decstbmReset = "\x1b[r"
+	scrollUp     = "\x1b[%dS"
+	scrollDown   = "\x1b[%dT"
+	decrc        = "\x1b8"
 	osc8         = "\x1b]8;%s;%s\x1b\\"
 	osc52put     = "\x1b]52;c;%s\x1b\\"
 	osc52pop     = "\x1b]52;c;?\x1b\\"
@@ -34,6 +48,13 @@ const (
 	osc777notify = "\x1b]777;notify;%s;%s\x1b\\"
 	setTitle     = "\x1b]2;%s\x1b\\"
 	mouseShape   = "\x1b]22;%s\x1b\\"
+	osc4query    = "\x1b]4;%d;?\x1b\\"
+	osc4set      = "\x1b]4;%d;%s\x1b\\"
+	osc10query   = "\x1b]10;?\x1b\\"
+	osc11query   = "\x1b]11;?\x1b\\"
+	osc12set     = "\x1b]12;%s\x1b\\"
+	osc104reset  = "\x1b]104;%d\x1b\\"
+	osc112reset  = "\x1b]112\x1b\\"
 
 	// SGR
 	sgrReset           = "\x1b[m"
@@ -72,12 +93,19 @@ const (
 	applicationMode = "\x1b="
 	numericMode     = "\x1b>"
 
+	// xterm modifyOtherKeys
+	modifyOtherKeys      = "\x1b[>4;%dm"
+	modifyOtherKeysReset = "\x1b[>4m"
+
 	// Private Modes
 	cursorKeys         = 1
 	cursorVisibility   = 25
+	mouseButtonEvents  = 1000
+	mouseDragEvents    = 1002
 	mouseAllEvents     = 1003
 	mouseFocusEvents   = 1004
 	mouseSGR           = 1006
+	mouseSGRPixels     = 1016
 	alternateScreen    = 1049
 	bracketedPaste     = 2004
 	synchronizedUpdate = 2026
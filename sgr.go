@@ -0,0 +1,61 @@
+package vaxis
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// sgrBuffer collects SGR sequences so that several style changes can be
+// written as a single sequence. Sequences are added in their complete form (eg
+// "\x1b[1m") and only their parameters are kept
+type sgrBuffer struct {
+	params []string
+	// legacy separates the parameters of extended colors with ';', for
+	// terminals which don't understand ':'
+	legacy bool
+}
+
+// add appends the parameters of an SGR sequence
+func (s *sgrBuffer) add(seq string) {
+	seq = strings.TrimPrefix(seq, "\x1b[")
+	seq = strings.TrimSuffix(seq, "m")
+	if s.legacy && isExtendedColor(seq) {
+		seq = strings.ReplaceAll(seq, ":", ";")
+	}
+	s.params = append(s.params, seq)
+}
+
+// isExtendedColor reports if the SGR parameters set an indexed or RGB color
+func isExtendedColor(params string) bool {
+	return strings.HasPrefix(params, "38:") ||
+		strings.HasPrefix(params, "48:") ||
+		strings.HasPrefix(params, "58:")
+}
+
+// addf formats an SGR sequence and appends its parameters
+func (s *sgrBuffer) addf(seq string, args ...any) {
+	s.add(fmt.Sprintf(seq, args...))
+}
+
+// flush writes the buffered parameters and resets the buffer. Parameters which
+// use ':' delimited subparameters are written as their own sequence: a terminal
+// which doesn't understand them may discard the entire sequence, and we don't
+// want to lose any other attributes along with it
+func (s *sgrBuffer) flush(w io.StringWriter) {
+	if len(s.params) == 0 {
+		return
+	}
+	merged := make([]string, 0, len(s.params))
+	for _, p := range s.params {
+		if strings.Contains(p, ":") {
+			_, _ = w.WriteString("\x1b[" + p + "m")
+			continue
+		}
+		merged = append(merged, p)
+	}
+	if len(merged) > 0 {
+		_, _ = w.WriteString("\x1b[" + strings.Join(merged, ";") + "m")
+	}
+	s.params = s.params[:0]
+}
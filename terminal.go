@@ -0,0 +1,100 @@
+package vaxis
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// TerminalInfo identifies the terminal Vaxis is running in
+type TerminalInfo struct {
+	// Name is the name of the terminal, such as "kitty" or "tmux". Name is
+	// empty if the terminal couldn't be identified
+	Name string
+	// Version is the version of the terminal, as reported by the terminal.
+	// Version is empty if unknown
+	Version string
+}
+
+func (t TerminalInfo) String() string {
+	if t.Version == "" {
+		return t.Name
+	}
+	return t.Name + " " + t.Version
+}
+
+// terminalReports are the identifying replies received from the terminal
+type terminalReports struct {
+	// xtversion is the XTVERSION reply, eg "kitty(0.31.0)"
+	xtversion string
+	// da2 are the secondary device attribute parameters: the terminal type,
+	// firmware version and ROM cartridge registration number
+	da2 []int
+	// da3 is the decoded tertiary device attribute unit ID
+	da3 string
+	// background is the reply to OSC 11, used to estimate the color theme
+	background Color
+}
+
+// identifyTerminal identifies the terminal from its replies to our queries and
+// the environment. XTVERSION is the most specific, and is preferred. DA2 and
+// DA3 only identify a handful of terminals. TERM_PROGRAM is last, as it is
+// inherited by programs running in nested terminals and over ssh
+func identifyTerminal(r terminalReports, getenv func(string) string) TerminalInfo {
+	// asciinema passes our queries to the terminal it runs in, but what we
+	// draw is played back by asciinema
+	if getenv("ASCIINEMA_REC") != "" {
+		return TerminalInfo{Name: "asciinema"}
+	}
+	if r.xtversion != "" {
+		return parseXTVersion(r.xtversion)
+	}
+	if r.da3 == "~VTE" {
+		info := TerminalInfo{Name: "VTE"}
+		if len(r.da2) > 1 && r.da2[0] == 65 {
+			// VTE reports 0.68.2 as 6802
+			info.Version = fmt.Sprintf("0.%d.%d", r.da2[1]/100, r.da2[1]%100)
+		}
+		return info
+	}
+	if len(r.da2) > 1 {
+		version := strconv.Itoa(r.da2[1])
+		switch r.da2[0] {
+		case 41:
+			return TerminalInfo{Name: "XTerm", Version: version}
+		case 77:
+			return TerminalInfo{Name: "mintty", Version: version}
+		case 83:
+			return TerminalInfo{Name: "screen", Version: version}
+		case 84:
+			// tmux always reports version 0
+			return TerminalInfo{Name: "tmux"}
+		}
+	}
+	if name := getenv("TERM_PROGRAM"); name != "" {
+		return TerminalInfo{
+			Name:    name,
+			Version: getenv("TERM_PROGRAM_VERSION"),
+		}
+	}
+	return TerminalInfo{}
+}
+
+// parseXTVersion parses an XTVERSION reply. Terminals reply with either
+// "name(version)" or "name version"
+func parseXTVersion(s string) TerminalInfo {
+	i := strings.IndexAny(s, " (")
+	if i < 0 {
+		return TerminalInfo{Name: s}
+	}
+	return TerminalInfo{
+		Name:    s[:i],
+		Version: strings.Trim(s[i:], " ()"),
+	}
+}
+
+// TerminalInfo returns the identity of the terminal, as reported by the
+// terminal or the environment
+func (vx *Vaxis) TerminalInfo() TerminalInfo {
+	return vx.terminal
+}
@@ -0,0 +1,344 @@
+package vaxis
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"git.sr.ht/~rockorager/vaxis/log"
+)
+
+// terminfo is an entry from the compiled terminfo database. Only the standard
+// capabilities we use are decoded, along with every extended capability
+type terminfo struct {
+	// names are the names of the terminal, the last is the description
+	names   []string
+	bools   map[string]bool
+	numbers map[string]int
+	strings map[string]string
+}
+
+// terminfoNumbers are the standard numeric capabilities we use, by their index
+// in the compiled entry
+var terminfoNumbers = map[int]string{
+	13: "colors",
+}
+
+// terminfoStrings are the standard string capabilities we use, by their index
+// in the compiled entry
+var terminfoStrings = map[int]string{
+	37:  "ech",
+	121: "rep",
+	// The user strings describe the cursor position and device
+	// attributes queries, and their replies
+	293: "u6",
+	294: "u7",
+	295: "u8",
+	296: "u9",
+}
+
+const (
+	terminfoMagic   = 0o432
+	terminfoMagic32 = 0o1036
+)
+
+var errTerminfoNotFound = errors.New("terminfo entry not found")
+
+// terminfoDirs returns the directories to search for terminfo entries, in the
+// order ncurses searches them
+func terminfoDirs(getenv func(string) string) []string {
+	if dir := getenv("TERMINFO"); dir != "" {
+		return []string{dir}
+	}
+	dirs := []string{}
+	if home := getenv("HOME"); home != "" {
+		dirs = append(dirs, filepath.Join(home, ".terminfo"))
+	}
+	defaults := []string{
+		"/etc/terminfo",
+		"/lib/terminfo",
+		"/usr/share/terminfo",
+		"/usr/lib/terminfo",
+		"/usr/local/share/terminfo",
+	}
+	if env := getenv("TERMINFO_DIRS"); env != "" {
+		for _, dir := range strings.Split(env, ":") {
+			if dir == "" {
+				// An empty entry is the system default
+				dirs = append(dirs, "/usr/share/terminfo")
+				continue
+			}
+			dirs = append(dirs, dir)
+		}
+	}
+	return append(dirs, defaults...)
+}
+
+// loadTerminfo finds and reads the terminfo entry for term
+func loadTerminfo(term string, getenv func(string) string) (*terminfo, error) {
+	if term == "" || strings.ContainsAny(term, "/\\") || strings.HasPrefix(term, ".") {
+		return nil, errTerminfoNotFound
+	}
+	for _, dir := range terminfoDirs(getenv) {
+		// Entries are stored in a directory named for their first
+		// character, or its hex value on case insensitive filesystems
+		for _, sub := range []string{term[:1], fmt.Sprintf("%x", term[0])} {
+			b, err := os.ReadFile(filepath.Join(dir, sub, term))
+			if err != nil {
+				continue
+			}
+			return parseTerminfo(b)
+		}
+	}
+	return nil, errTerminfoNotFound
+}
+
+// terminfoReader reads the little endian values of a compiled entry
+type terminfoReader struct {
+	b   []byte
+	pos int
+	err error
+}
+
+func (r *terminfoReader) bytes(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || r.pos+n > len(r.b) {
+		r.err = errors.New("truncated terminfo entry")
+		return nil
+	}
+	b := r.b[r.pos : r.pos+n]
+	r.pos += n
+	return b
+}
+
+func (r *terminfoReader) int16() int {
+	b := r.bytes(2)
+	if b == nil {
+		return 0
+	}
+	return int(int16(binary.LittleEndian.Uint16(b)))
+}
+
+func (r *terminfoReader) int32() int {
+	b := r.bytes(4)
+	if b == nil {
+		return 0
+	}
+	return int(int32(binary.LittleEndian.Uint32(b)))
+}
+
+// align skips a byte to align to an even offset
+func (r *terminfoReader) align() {
+	if r.pos%2 == 1 {
+		r.bytes(1)
+	}
+}
+
+// number reads a numeric capability, which are 32 bit in the extended number
+// format
+func (r *terminfoReader) number(wide bool) int {
+	if wide {
+		return r.int32()
+	}
+	return r.int16()
+}
+
+// cstring returns the NUL terminated string at offset in table
+func cstring(table []byte, offset int) (string, bool) {
+	if offset < 0 || offset >= len(table) {
+		return "", false
+	}
+	end := bytes.IndexByte(table[offset:], 0)
+	if end < 0 {
+		return "", false
+	}
+	return string(table[offset : offset+end]), true
+}
+
+// parseTerminfo parses a compiled terminfo entry, as described in term(5)
+func parseTerminfo(b []byte) (*terminfo, error) {
+	r := &terminfoReader{b: b}
+	magic := r.int16()
+	var wide bool
+	switch magic {
+	case terminfoMagic:
+	case terminfoMagic32:
+		wide = true
+	default:
+		return nil, fmt.Errorf("invalid terminfo magic: %o", magic)
+	}
+	namesSize := r.int16()
+	boolCount := r.int16()
+	numCount := r.int16()
+	strCount := r.int16()
+	tableSize := r.int16()
+
+	ti := &terminfo{
+		bools:   make(map[string]bool),
+		numbers: make(map[string]int),
+		strings: make(map[string]string),
+	}
+	names := r.bytes(namesSize)
+	ti.names = strings.Split(strings.TrimRight(string(names), "\x00"), "|")
+	// No standard booleans are used
+	r.bytes(boolCount)
+	r.align()
+	for i := 0; i < numCount; i += 1 {
+		n := r.number(wide)
+		if name, ok := terminfoNumbers[i]; ok && n >= 0 {
+			ti.numbers[name] = n
+		}
+	}
+	offsets := make([]int, strCount)
+	for i := range offsets {
+		offsets[i] = r.int16()
+	}
+	table := r.bytes(tableSize)
+	if r.err != nil {
+		return nil, r.err
+	}
+	for i, off := range offsets {
+		name, ok := terminfoStrings[i]
+		if !ok {
+			continue
+		}
+		if s, ok := cstring(table, off); ok {
+			ti.strings[name] = s
+		}
+	}
+
+	r.align()
+	if r.pos >= len(b) {
+		// No extended capabilities
+		return ti, nil
+	}
+	err := ti.parseExtended(r, wide)
+	if err != nil {
+		return nil, err
+	}
+	return ti, nil
+}
+
+// parseExtended parses the extended capabilities which follow the standard
+// capabilities
+func (ti *terminfo) parseExtended(r *terminfoReader, wide bool) error {
+	boolCount := r.int16()
+	numCount := r.int16()
+	strCount := r.int16()
+	// The number of strings in the table, and its size
+	_ = r.int16()
+	tableSize := r.int16()
+
+	bools := r.bytes(boolCount)
+	r.align()
+	numbers := make([]int, numCount)
+	for i := range numbers {
+		numbers[i] = r.number(wide)
+	}
+	values := make([]int, strCount)
+	for i := range values {
+		values[i] = r.int16()
+	}
+	names := make([]int, boolCount+numCount+strCount)
+	for i := range names {
+		names[i] = r.int16()
+	}
+	table := r.bytes(tableSize)
+	if r.err != nil {
+		return r.err
+	}
+
+	// The table has the string values followed by the capability names.
+	// Name offsets are relative to the end of the values
+	nameStart := 0
+	strs := make([]string, strCount)
+	valid := make([]bool, strCount)
+	for i, off := range values {
+		s, ok := cstring(table, off)
+		if !ok {
+			continue
+		}
+		strs[i] = s
+		valid[i] = true
+		if end := off + len(s) + 1; end > nameStart {
+			nameStart = end
+		}
+	}
+	name := func(i int) (string, bool) {
+		return cstring(table, nameStart+names[i])
+	}
+	for i, v := range bools {
+		if n, ok := name(i); ok && v == 1 {
+			ti.bools[n] = true
+		}
+	}
+	for i, v := range numbers {
+		if n, ok := name(boolCount + i); ok && v >= 0 {
+			ti.numbers[n] = v
+		}
+	}
+	for i := range strs {
+		if n, ok := name(boolCount + numCount + i); ok && valid[i] {
+			ti.strings[n] = strs[i]
+		}
+	}
+	return nil
+}
+
+// applyTerminfo seeds our capabilities from the terminfo entry. These are
+// only ever turned on: the terminal may support more than its entry describes,
+// which we'll learn from its replies to our queries. The entry decides which
+// sequences we use, but not how they are encoded: we always write the ECMA-48
+// and xterm forms
+func (vx *Vaxis) applyTerminfo() {
+	ti := vx.terminfo
+	if ti == nil {
+		return
+	}
+	log.Info("[terminfo] using entry %s", ti.names[0])
+	colors := ti.numbers["colors"]
+	if ti.bools["RGB"] || ti.bools["Tc"] || colors >= 1<<24 {
+		log.Info("[terminfo] RGB")
+		vx.caps.rgb = true
+	}
+	if _, ok := ti.strings["Smulx"]; ok {
+		log.Info("[terminfo] Styled underlines")
+		vx.caps.styledUnderlines = true
+	}
+	// Entries such as xterm-256color describe rep, while many terminals
+	// using them don't implement it. Terminals which answer our queries are
+	// asked with XTGETTCAP instead
+	if _, ok := ti.strings["rep"]; ok && !ti.answersQueries() {
+		log.Info("[terminfo] Repeat character")
+		vx.caps.repeatCharacter = true
+	}
+	vx.caps.colors = colors
+}
+
+// answersQueries reports if the terminal is expected to reply to our queries.
+// The Linux console, dumb terminals and VT100 compatibles don't, nor do
+// terminals whose entry doesn't describe the cursor position and device
+// attributes queries
+func (ti *terminfo) answersQueries() bool {
+	name := ti.names[0]
+	switch {
+	case name == "linux", strings.HasPrefix(name, "linux-"):
+		return false
+	case name == "dumb":
+		return false
+	case len(name) > 2 && strings.HasPrefix(name, "vt") && name[2] >= '0' && name[2] <= '9':
+		return false
+	}
+	for _, cap := range []string{"u6", "u7", "u8", "u9"} {
+		if _, ok := ti.strings[cap]; ok {
+			return true
+		}
+	}
+	return false
+}
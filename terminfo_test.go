@@ -0,0 +1,171 @@
+package vaxis
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testdata/terminfo is compiled from testdata/terminfo/vaxis.ti with:
+//
+//	tic -x -o testdata/terminfo testdata/terminfo/vaxis.ti
+func testTerminfoEnv(key string) string {
+	if key == "TERMINFO" {
+		return "testdata/terminfo"
+	}
+	return ""
+}
+
+func TestLoadTerminfo(t *testing.T) {
+	ti, err := loadTerminfo("vaxis-test", testTerminfoEnv)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"vaxis-test", "terminfo test entry"}, ti.names)
+	assert.Equal(t, map[string]bool{"Tc": true}, ti.bools)
+	assert.Equal(t, map[string]int{"colors": 256, "Xn": 7}, ti.numbers)
+	assert.Equal(t, map[string]string{
+		"ech":   "\x1b[%p1%dX",
+		"rep":   "%p1%c\x1b[%p2%{1}%-%db",
+		"Smulx": "\x1b[4:%p1%dm",
+		"Ss":    "\x1b[%p1%d q",
+		"u6":    "\x1b[%i%d;%dR",
+		"u7":    "\x1b[6n",
+		"u8":    "\x1b[?%[;0123456789]c",
+		"u9":    "\x1b[c",
+	}, ti.strings)
+
+	// Entries with numbers too large for 16 bits use the 32 bit format
+	ti, err = loadTerminfo("vaxis-direct", testTerminfoEnv)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"RGB": true}, ti.bools)
+	assert.Equal(t, map[string]int{"colors": 1 << 24}, ti.numbers)
+	assert.Equal(t, map[string]string{}, ti.strings)
+
+	_, err = loadTerminfo("vaxis-missing", testTerminfoEnv)
+	assert.Error(t, err)
+	_, err = loadTerminfo("../v/vaxis-test", testTerminfoEnv)
+	assert.Error(t, err)
+}
+
+func TestLoadTerminfoHexDir(t *testing.T) {
+	b, err := os.ReadFile("testdata/terminfo/v/vaxis-test")
+	require.NoError(t, err)
+	dir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "76"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "76", "vaxis-test"), b, 0o644))
+
+	ti, err := loadTerminfo("vaxis-test", func(key string) string {
+		if key == "TERMINFO_DIRS" {
+			return dir
+		}
+		return ""
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "vaxis-test", ti.names[0])
+}
+
+func TestParseTerminfoInvalid(t *testing.T) {
+	b, err := os.ReadFile("testdata/terminfo/v/vaxis-test")
+	require.NoError(t, err)
+	_, err = parseTerminfo(b[:40])
+	assert.Error(t, err)
+	_, err = parseTerminfo([]byte("not a terminfo entry"))
+	assert.Error(t, err)
+}
+
+func TestApplyTerminfo(t *testing.T) {
+	vx := &Vaxis{}
+	vx.terminfo, _ = loadTerminfo("vaxis-test", testTerminfoEnv)
+	vx.applyTerminfo()
+	assert.Equal(t, capabilities{
+		rgb:              true,
+		styledUnderlines: true,
+		colors:           256,
+	}, vx.caps)
+
+	// rep is only trusted from terminals which can't be asked
+	vx = &Vaxis{}
+	vx.terminfo = &terminfo{
+		names:   []string{"vt420"},
+		strings: map[string]string{"rep": "%p1%c\x1b[%p2%{1}%-%db"},
+	}
+	vx.applyTerminfo()
+	assert.Equal(t, capabilities{repeatCharacter: true}, vx.caps)
+
+	vx = &Vaxis{}
+	vx.terminfo, _ = loadTerminfo("vaxis-direct", testTerminfoEnv)
+	vx.applyTerminfo()
+	assert.Equal(t, capabilities{
+		rgb:    true,
+		colors: 1 << 24,
+	}, vx.caps)
+}
+
+func TestTerminfoAnswersQueries(t *testing.T) {
+	tests := []struct {
+		name     string
+		strings  map[string]string
+		expected bool
+	}{
+		{
+			name:     "xterm-256color",
+			strings:  map[string]string{"u8": "\x1b[?%[;0123456789]c", "u9": "\x1b[c"},
+			expected: true,
+		},
+		{
+			name:     "vte-256color",
+			strings:  map[string]string{"u7": "\x1b[6n"},
+			expected: true,
+		},
+		{
+			name:     "no queries",
+			strings:  map[string]string{},
+			expected: false,
+		},
+		{
+			name:     "linux",
+			strings:  map[string]string{"u8": "\x1b[?6c", "u9": "\x1b[c"},
+			expected: false,
+		},
+		{
+			name:     "vt100",
+			strings:  map[string]string{"u8": "\x1b[?%[;0123456789]c", "u9": "\x1bZ"},
+			expected: false,
+		},
+		{
+			name:     "dumb",
+			strings:  map[string]string{},
+			expected: false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ti := &terminfo{
+				names:   []string{test.name},
+				strings: test.strings,
+			}
+			assert.Equal(t, test.expected, ti.answersQueries())
+		})
+	}
+
+	ti, err := loadTerminfo("vaxis-test", testTerminfoEnv)
+	require.NoError(t, err)
+	assert.True(t, ti.answersQueries())
+	ti, err = loadTerminfo("vaxis-direct", testTerminfoEnv)
+	require.NoError(t, err)
+	assert.False(t, ti.answersQueries())
+}
+
+func TestRenderWithoutECH(t *testing.T) {
+	vx, out := newTestVaxis(30, 1)
+	vx.terminfo, _ = loadTerminfo("vaxis-direct", testTerminfoEnv)
+	renderLines(vx, []string{strings.Repeat("x", 20) + " end"})
+	out.Reset()
+
+	// The entry has no ech, so the blanks are printed
+	renderLines(vx, []string{strings.Repeat(" ", 21) + "end"})
+	assert.Equal(t, "\x1b[1;1H"+strings.Repeat(" ", 20)+"\x1b[m", out.String())
+}
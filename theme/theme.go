@@ -0,0 +1,152 @@
+// Package theme provides styles for semantic roles, with variants for light
+// and dark terminals. The variant is chosen from the terminal's color theme,
+// which Vaxis tracks from [vaxis.ColorThemeUpdate] reports, or estimates from
+// the terminal's background color when those aren't supported.
+//
+// The built in widgets use the roles of the current theme unless they are
+// given a style
+package theme
+
+import (
+	"sync"
+
+	"git.sr.ht/~rockorager/vaxis"
+)
+
+// Role is the purpose of a style
+type Role int
+
+const (
+	// Normal is regular text
+	Normal Role = iota
+	// Primary is for accented, interactive elements such as buttons and
+	// prompts
+	Primary
+	// Muted is for secondary, less important text
+	Muted
+	// Error is for errors
+	Error
+	// Warning is for warnings
+	Warning
+	// Success is for successful results
+	Success
+	// Selection is for selected items and text
+	Selection
+	// Border is for borders and separators
+	Border
+)
+
+func (r Role) String() string {
+	switch r {
+	case Normal:
+		return "normal"
+	case Primary:
+		return "primary"
+	case Muted:
+		return "muted"
+	case Error:
+		return "error"
+	case Warning:
+		return "warning"
+	case Success:
+		return "success"
+	case Selection:
+		return "selection"
+	case Border:
+		return "border"
+	default:
+		return "unknown"
+	}
+}
+
+// Variant is the style of a role on light and dark terminals
+type Variant struct {
+	Light vaxis.Style
+	Dark  vaxis.Style
+}
+
+// Theme maps roles to their styles. Roles which aren't in the theme use the
+// default style
+type Theme map[Role]Variant
+
+// Style returns the style of the role for the color theme mode. Dark styles
+// are used when the mode is unknown
+func (t Theme) Style(mode vaxis.ColorThemeMode, role Role) vaxis.Style {
+	v := t[role]
+	if mode == vaxis.LightMode {
+		return v.Light
+	}
+	return v.Dark
+}
+
+// Default is the built in theme. It only uses the 16 ANSI colors so that it
+// follows the terminal's palette
+var Default = Theme{
+	Primary: {
+		Light: vaxis.Style{Foreground: vaxis.IndexColor(4)},
+		Dark:  vaxis.Style{Foreground: vaxis.IndexColor(12)},
+	},
+	Muted: {
+		Light: vaxis.Style{Attribute: vaxis.AttrDim},
+		Dark:  vaxis.Style{Attribute: vaxis.AttrDim},
+	},
+	Error: {
+		Light: vaxis.Style{Foreground: vaxis.IndexColor(1)},
+		Dark:  vaxis.Style{Foreground: vaxis.IndexColor(9)},
+	},
+	Warning: {
+		Light: vaxis.Style{Foreground: vaxis.IndexColor(3)},
+		Dark:  vaxis.Style{Foreground: vaxis.IndexColor(11)},
+	},
+	Success: {
+		Light: vaxis.Style{Foreground: vaxis.IndexColor(2)},
+		Dark:  vaxis.Style{Foreground: vaxis.IndexColor(10)},
+	},
+	Selection: {
+		Light: vaxis.Style{Attribute: vaxis.AttrReverse},
+		Dark:  vaxis.Style{Attribute: vaxis.AttrReverse},
+	},
+	Border: {
+		Light: vaxis.Style{Foreground: vaxis.IndexColor(7)},
+		Dark:  vaxis.Style{Foreground: vaxis.IndexColor(8)},
+	},
+}
+
+var (
+	mu      sync.RWMutex
+	current = Default
+)
+
+// Set makes t the current theme
+func Set(t Theme) {
+	mu.Lock()
+	defer mu.Unlock()
+	current = t
+}
+
+// Current returns the current theme
+func Current() Theme {
+	mu.RLock()
+	defer mu.RUnlock()
+	return current
+}
+
+// Style returns the style of the role in the current theme, for the color
+// theme of vx. vx may be nil, in which case the dark style is used
+func Style(vx *vaxis.Vaxis, role Role) vaxis.Style {
+	var mode vaxis.ColorThemeMode
+	if vx != nil {
+		mode = vx.ColorThemeMode()
+	}
+	return Current().Style(mode, role)
+}
+
+// Or returns style, unless it is the zero style in which case the style of
+// the role in the current theme is returned. Widgets use Or so that styles
+// set by the application take precedence over the theme
+func Or(vx *vaxis.Vaxis, style vaxis.Style, role Role) vaxis.Style {
+	if style != (vaxis.Style{}) {
+		return style
+	}
+	return Style(vx, role)
+}
@@ -0,0 +1,76 @@
+package theme_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"git.sr.ht/~rockorager/vaxis"
+	"git.sr.ht/~rockorager/vaxis/theme"
+)
+
+func TestStyle(t *testing.T) {
+	th := theme.Theme{
+		theme.Primary: {
+			Light: vaxis.Style{Foreground: vaxis.IndexColor(4)},
+			Dark:  vaxis.Style{Foreground: vaxis.IndexColor(12)},
+		},
+	}
+	tests := []struct {
+		name     string
+		mode     vaxis.ColorThemeMode
+		role     theme.Role
+		expected vaxis.Style
+	}{
+		{
+			name:     "light",
+			mode:     vaxis.LightMode,
+			role:     theme.Primary,
+			expected: vaxis.Style{Foreground: vaxis.IndexColor(4)},
+		},
+		{
+			name:     "dark",
+			mode:     vaxis.DarkMode,
+			role:     theme.Primary,
+			expected: vaxis.Style{Foreground: vaxis.IndexColor(12)},
+		},
+		{
+			name:     "unknown mode",
+			role:     theme.Primary,
+			expected: vaxis.Style{Foreground: vaxis.IndexColor(12)},
+		},
+		{
+			name:     "missing role",
+			mode:     vaxis.LightMode,
+			role:     theme.Error,
+			expected: vaxis.Style{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, th.Style(test.mode, test.role))
+		})
+	}
+}
+
+func TestCurrent(t *testing.T) {
+	defer theme.Set(theme.Default)
+	assert.Equal(t, theme.Default, theme.Current())
+	assert.Equal(t, vaxis.Style{Foreground: vaxis.IndexColor(9)}, theme.Style(nil, theme.Error))
+
+	custom := theme.Theme{
+		theme.Error: {
+			Light: vaxis.Style{Foreground: vaxis.HexColor(0xAA0000)},
+			Dark:  vaxis.Style{Foreground: vaxis.HexColor(0xFF5555)},
+		},
+	}
+	theme.Set(custom)
+	assert.Equal(t, vaxis.Style{Foreground: vaxis.HexColor(0xFF5555)}, theme.Style(nil, theme.Error))
+	assert.Equal(t, vaxis.Style{}, theme.Style(nil, theme.Primary))
+}
+
+func TestOr(t *testing.T) {
+	style := vaxis.Style{Attribute: vaxis.AttrBold}
+	assert.Equal(t, style, theme.Or(nil, style, theme.Border))
+	assert.Equal(t, theme.Default.Style(vaxis.DarkMode, theme.Border), theme.Or(nil, vaxis.Style{}, theme.Border))
+}
@@ -0,0 +1,302 @@
+package vaxis
+
+import (
+	"sync"
+	"time"
+)
+
+// Tick is delivered each time a [Ticker] fires
+type Tick struct {
+	// Ticker is the Ticker which fired
+	Ticker *Ticker
+	// Time is the time the tick fired
+	Time time.Time
+	// Missed is the number of ticks which were dropped since the last Tick
+	// was delivered. Ticks are dropped while the application hasn't yet
+	// received the previous Tick from the same Ticker
+	Missed int
+}
+
+// clock holds the active timers and tickers. All timers are paused while Vaxis
+// is suspended
+type clock struct {
+	mu      sync.Mutex
+	paused  bool
+	timers  map[*Timer]struct{}
+	tickers map[*Ticker]struct{}
+}
+
+// Timer delivers a single event after a delay. Create a Timer with
+// [Vaxis.After]
+type Timer struct {
+	vx *Vaxis
+	ev Event
+	// remaining is the time left before the timer fires, measured from when
+	// it was last started
+	remaining time.Duration
+	deadline  time.Time
+	timer     *time.Timer
+	// gen identifies the current timer, so that a callback of a timer
+	// stopped while it was firing does nothing
+	gen     int
+	stopped bool
+}
+
+// After posts ev to the event queue after d has elapsed. The returned Timer
+// can be used to cancel the event. Time spent suspended does not count towards
+// d
+func (vx *Vaxis) After(d time.Duration, ev Event) *Timer {
+	c := &vx.clock
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.timers == nil {
+		c.timers = make(map[*Timer]struct{})
+	}
+	t := &Timer{
+		vx:        vx,
+		ev:        ev,
+		remaining: d,
+	}
+	c.timers[t] = struct{}{}
+	if !c.paused {
+		t.start()
+	}
+	return t
+}
+
+// Stop cancels the timer. Stop returns false if the timer has already fired or
+// been stopped
+func (t *Timer) Stop() bool {
+	c := &t.vx.clock
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if t.stopped {
+		return false
+	}
+	t.stopped = true
+	if t.timer != nil {
+		t.timer.Stop()
+	}
+	delete(c.timers, t)
+	return true
+}
+
+// start starts the timer. The clock must be locked
+func (t *Timer) start() {
+	t.deadline = time.Now().Add(t.remaining)
+	t.gen += 1
+	gen := t.gen
+	t.timer = time.AfterFunc(t.remaining, func() { t.fire(gen) })
+}
+
+// pause stops the timer, remembering how long it had left. The clock must be
+// locked
+func (t *Timer) pause() {
+	t.timer.Stop()
+	t.remaining = time.Until(t.deadline)
+	if t.remaining < 0 {
+		t.remaining = 0
+	}
+}
+
+func (t *Timer) fire(gen int) {
+	c := &t.vx.clock
+	c.mu.Lock()
+	if t.stopped || c.paused || gen != t.gen {
+		// If we were paused as we fired, we'll fire again on resume
+		c.mu.Unlock()
+		return
+	}
+	t.stopped = true
+	delete(c.timers, t)
+	c.mu.Unlock()
+	t.vx.PostEvent(t.ev)
+}
+
+// Ticker delivers a [Tick] event at a regular interval. Create a Ticker with
+// [Vaxis.Every]
+type Ticker struct {
+	vx       *Vaxis
+	interval time.Duration
+	// remaining is the time left before the next tick, measured from when
+	// the ticker was last started
+	remaining time.Duration
+	next      time.Time
+	timer     *time.Timer
+	// gen identifies the current timer, so that a callback of a timer
+	// stopped while it was firing doesn't start a second chain of ticks
+	gen     int
+	stopped bool
+	// pending is true while a Tick has been posted but not yet received by
+	// the application
+	pending bool
+	missed  int
+}
+
+// Every posts a [Tick] event every d until the returned Ticker is stopped. If
+// the application falls behind, ticks are coalesced: a Ticker never has more
+// than one Tick waiting in the event queue. Tickers are paused while Vaxis is
+// suspended
+func (vx *Vaxis) Every(d time.Duration) *Ticker {
+	if d <= 0 {
+		panic("vaxis: non-positive interval for Every")
+	}
+	c := &vx.clock
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.tickers == nil {
+		c.tickers = make(map[*Ticker]struct{})
+	}
+	t := &Ticker{
+		vx:        vx,
+		interval:  d,
+		remaining: d,
+	}
+	c.tickers[t] = struct{}{}
+	if !c.paused {
+		t.start()
+	}
+	return t
+}
+
+// Stop stops the ticker. No more ticks will be posted, though a Tick may
+// already be in the event queue
+func (t *Ticker) Stop() {
+	c := &t.vx.clock
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if t.stopped {
+		return
+	}
+	t.stopped = true
+	if t.timer != nil {
+		t.timer.Stop()
+	}
+	delete(c.tickers, t)
+}
+
+// start schedules the next tick. The clock must be locked
+func (t *Ticker) start() {
+	t.next = time.Now().Add(t.remaining)
+	t.schedule()
+}
+
+// schedule starts a timer for the next tick. The clock must be locked
+func (t *Ticker) schedule() {
+	t.gen += 1
+	gen := t.gen
+	t.timer = time.AfterFunc(t.remaining, func() { t.fire(gen) })
+}
+
+// pause stops the ticker, remembering how long until the next tick. The clock
+// must be locked
+func (t *Ticker) pause() {
+	t.timer.Stop()
+	t.remaining = time.Until(t.next)
+	if t.remaining < 0 {
+		t.remaining = 0
+	}
+}
+
+func (t *Ticker) fire(gen int) {
+	c := &t.vx.clock
+	c.mu.Lock()
+	if t.stopped || c.paused || gen != t.gen {
+		c.mu.Unlock()
+		return
+	}
+	now := time.Now()
+	// Schedule the next tick, skipping any we've completely missed
+	t.next = t.next.Add(t.interval)
+	for !t.next.After(now) {
+		t.next = t.next.Add(t.interval)
+		t.missed += 1
+	}
+	t.remaining = t.next.Sub(now)
+	t.schedule()
+
+	if t.pending {
+		// The application hasn't received the last tick yet
+		t.missed += 1
+		c.mu.Unlock()
+		return
+	}
+	tick := Tick{
+		Ticker: t,
+		Time:   now,
+		Missed: t.missed,
+	}
+	t.missed = 0
+	t.pending = true
+	c.mu.Unlock()
+	if !t.vx.postEvent(tick) {
+		c.mu.Lock()
+		t.pending = false
+		c.mu.Unlock()
+	}
+}
+
+// received is called when the application has received a Tick
+func (t *Ticker) received() {
+	c := &t.vx.clock
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t.pending = false
+}
+
+// pauseClock pauses all timers and tickers
+func (vx *Vaxis) pauseClock() {
+	c := &vx.clock
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.paused {
+		return
+	}
+	c.paused = true
+	for t := range c.timers {
+		t.pause()
+	}
+	for t := range c.tickers {
+		t.pause()
+	}
+}
+
+// resumeClock restarts all timers and tickers with the time they had left when
+// they were paused
+func (vx *Vaxis) resumeClock() {
+	c := &vx.clock
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.paused {
+		return
+	}
+	c.paused = false
+	for t := range c.timers {
+		t.start()
+	}
+	for t := range c.tickers {
+		t.start()
+	}
+}
+
+// stopClock stops all timers and tickers
+func (vx *Vaxis) stopClock() {
+	c := &vx.clock
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.paused = true
+	for t := range c.timers {
+		t.stopped = true
+		if t.timer != nil {
+			t.timer.Stop()
+		}
+	}
+	for t := range c.tickers {
+		t.stopped = true
+		if t.timer != nil {
+			t.timer.Stop()
+		}
+	}
+	c.timers = nil
+	c.tickers = nil
+}
@@ -0,0 +1,159 @@
+package vaxis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type timerEvent struct{}
+
+// newClockVaxis returns a Vaxis with a running event forwarder but no terminal
+func newClockVaxis(t *testing.T) *Vaxis {
+	vx := &Vaxis{
+		queue:  make(chan Event, 16),
+		events: make(chan Event),
+		chQuit: make(chan bool),
+	}
+	go vx.forwardEvents()
+	t.Cleanup(func() {
+		vx.stopClock()
+		close(vx.chQuit)
+	})
+	return vx
+}
+
+// nextEvent waits up to d for an event
+func nextEvent(vx *Vaxis, d time.Duration) Event {
+	select {
+	case ev := <-vx.events:
+		return ev
+	case <-time.After(d):
+		return nil
+	}
+}
+
+func TestAfter(t *testing.T) {
+	vx := newClockVaxis(t)
+	start := time.Now()
+	vx.After(20*time.Millisecond, timerEvent{})
+	assert.Equal(t, timerEvent{}, nextEvent(vx, time.Second))
+	assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+}
+
+func TestAfterStop(t *testing.T) {
+	vx := newClockVaxis(t)
+	timer := vx.After(20*time.Millisecond, timerEvent{})
+	assert.True(t, timer.Stop())
+	assert.False(t, timer.Stop())
+	assert.Nil(t, nextEvent(vx, 50*time.Millisecond))
+}
+
+func TestEvery(t *testing.T) {
+	vx := newClockVaxis(t)
+	ticker := vx.Every(10 * time.Millisecond)
+	for i := 0; i < 3; i += 1 {
+		ev := nextEvent(vx, time.Second)
+		require.IsType(t, Tick{}, ev)
+		assert.Equal(t, ticker, ev.(Tick).Ticker)
+	}
+	ticker.Stop()
+	// A tick may have been posted before we stopped
+	nextEvent(vx, 20*time.Millisecond)
+	assert.Nil(t, nextEvent(vx, 50*time.Millisecond))
+}
+
+func TestEveryCoalesces(t *testing.T) {
+	vx := newClockVaxis(t)
+	ticker := vx.Every(10 * time.Millisecond)
+	defer ticker.Stop()
+
+	// Don't receive anything for a while: only one tick is queued, and it
+	// reports the ticks dropped while waiting
+	time.Sleep(100 * time.Millisecond)
+	ev := nextEvent(vx, time.Second)
+	require.IsType(t, Tick{}, ev)
+	assert.Equal(t, 0, ev.(Tick).Missed)
+	ev = nextEvent(vx, time.Second)
+	require.IsType(t, Tick{}, ev)
+	assert.Greater(t, ev.(Tick).Missed, 4)
+	assert.Empty(t, vx.queue)
+}
+
+func TestClockPause(t *testing.T) {
+	vx := newClockVaxis(t)
+	vx.After(30*time.Millisecond, timerEvent{})
+	ticker := vx.Every(10 * time.Millisecond)
+	defer ticker.Stop()
+
+	vx.pauseClock()
+	// Drain anything posted before we paused
+	for nextEvent(vx, 20*time.Millisecond) != nil {
+	}
+	assert.Nil(t, nextEvent(vx, 50*time.Millisecond))
+
+	vx.resumeClock()
+	timerFired := false
+	ticked := false
+	for !timerFired || !ticked {
+		switch ev := nextEvent(vx, time.Second).(type) {
+		case timerEvent:
+			timerFired = true
+		case Tick:
+			ticked = true
+		default:
+			t.Fatalf("unexpected event: %#v", ev)
+		}
+	}
+}
+
+func TestEventsAfterQuit(t *testing.T) {
+	vx := newClockVaxis(t)
+	vx.PostEvent(QuitEvent{})
+	vx.PostEvent(timerEvent{})
+	assert.Equal(t, QuitEvent{}, nextEvent(vx, time.Second))
+	// The events channel is never closed, so loops selecting on it don't
+	// spin after Vaxis is closed
+	assert.Nil(t, nextEvent(vx, 20*time.Millisecond))
+}
+
+func TestForwardEventsClose(t *testing.T) {
+	vx := &Vaxis{
+		queue:  make(chan Event, 16),
+		events: make(chan Event),
+		chQuit: make(chan bool),
+	}
+	done := make(chan struct{})
+	go func() {
+		vx.forwardEvents()
+		close(done)
+	}()
+	// Nobody reads the events, but closing still stops forwarding
+	vx.PostEvent(timerEvent{})
+	close(vx.chQuit)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("forwardEvents didn't return")
+	}
+}
+
+func TestTickerStaleFire(t *testing.T) {
+	vx := newClockVaxis(t)
+	c := &vx.clock
+	ticker := vx.Every(time.Millisecond)
+	defer ticker.Stop()
+
+	// Let the timer fire while we hold the clock, then restart the ticker
+	// as resumeClock would. The blocked callback belongs to the old timer,
+	// and must not post a tick or schedule another one
+	c.mu.Lock()
+	time.Sleep(10 * time.Millisecond)
+	ticker.pause()
+	ticker.remaining = time.Hour
+	ticker.start()
+	c.mu.Unlock()
+	assert.Nil(t, nextEvent(vx, 50*time.Millisecond))
+}
@@ -5,8 +5,10 @@ import (
 	"bytes"
 	"context"
 	"encoding/base64"
+	"encoding/hex"
 	"io"
 	"os"
+	"os/exec"
 	"os/signal"
 	"strings"
 	"sync"
@@ -30,6 +32,11 @@ type capabilities struct {
 	colorThemeUpdates  bool
 	reportSizeChars    bool
 	reportSizePixels   bool
+	repeatCharacter    bool
+	sgrPixels          bool
+	// colors is the number of colors in the palette, from terminfo. Zero
+	// if unknown
+	colors int
 }
 
 type cursorState struct {
@@ -43,27 +50,78 @@ type cursorState struct {
 // object at instantiation
 type Options struct {
 	// DisableKittyKeyboard disables the use of the Kitty Keyboard protocol.
-	// By default, if support is detected the protocol will be used.
+	// By default, if support is detected the protocol will be used. This is
+	// the same as setting Capabilities.KittyKeyboard to CapabilityOff
 	DisableKittyKeyboard bool
 	// ReportKeyboardEvents will report key release and key repeat events if
-	// KittyKeyboardProtocol is enabled and supported by the terminal
+	// KittyKeyboardProtocol is enabled and supported by the terminal. This
+	// is the same as adding KeyboardReportEvents to KeyboardFlags
 	ReportKeyboardEvents bool
+	// KeyboardFlags are the progressive enhancements of the kitty keyboard
+	// protocol to request. Zero requests all of them except
+	// KeyboardReportEvents. Applications which need release events of
+	// Enter, Tab and Backspace must include KeyboardAllKeys
+	KeyboardFlags KeyboardFlags
+	// EscTimeout is how long to wait for the rest of an escape sequence
+	// after an Escape before reporting the Escape key. Defaults to 10ms. A
+	// longer timeout helps over slow connections, where a sequence such as
+	// Alt+key can be split and read as Escape followed by the key
+	EscTimeout time.Duration
 	// The size of the event queue channel. This will default to 1024 to
 	// prevent any blocking on writes.
 	EventQueueSize int
 	// Disable mouse events
 	DisableMouse bool
+	// MouseMode selects which mouse events are reported. Reporting fewer
+	// events cuts input traffic. Defaults to MouseAnyMotion
+	MouseMode MouseMode
+	// MousePixels reports the position of mouse events in pixels, if the
+	// terminal supports SGR-Pixels (mode 1016) and reports its size in
+	// pixels. See [Mouse]
+	MousePixels bool
 	// WithTTY passes an absolute path to use for the TTY Vaxis will draw
 	// on. If the file is not a TTY, an error will be returned when calling
 	// New
 	WithTTY string
 	// NoSignals causes Vaxis to not install any signal handlers
 	NoSignals bool
+	// Inline renders Vaxis in the primary screen, below the current cursor
+	// position, instead of entering the alternate screen. Rows are reserved
+	// by scrolling the terminal as needed, and the final frame is left in
+	// place when Vaxis is closed. The [Window] returned by [Vaxis.Window]
+	// covers only the inline region
+	Inline bool
+	// InlineHeight is the number of rows used when rendering inline. If
+	// zero or larger than the terminal, the full height of the terminal is
+	// used
+	InlineHeight int
+	// InlineGrow treats InlineHeight as a maximum height. The inline region
+	// starts as a single row and grows as rows are drawn to
+	InlineGrow bool
+	// MaxFPS is the maximum rate at which Redraw events requested with
+	// [Vaxis.RequestRender] are posted. Defaults to 60. A negative value
+	// disables the limit
+	MaxFPS int
+	// JobControl stops the process when Ctrl+Z is pressed or SIGTSTP is
+	// received, the same as a program running in cooked mode. Vaxis is
+	// suspended before stopping, and resumed when the process is
+	// continued. Only supported on unix platforms
+	JobControl bool
+	// ColorDepth sets the number of colors used when rendering. By default,
+	// the depth is detected from the terminal's capabilities and its
+	// terminfo entry. If the NO_COLOR environment variable is set, the
+	// default is ColorDepthMonochrome
+	ColorDepth ColorDepth
+	// Capabilities forces detected capabilities on or off. This can be used
+	// to work around terminals which report capabilities incorrectly
+	Capabilities CapabilityOverrides
 }
 
 type Vaxis struct {
 	queue            chan Event
+	events           chan Event
 	console          console.Console
+	tty              *os.File
 	parser           *ansi.Parser
 	tw               *writer
 	screenNext       *screen
@@ -72,16 +130,27 @@ type Vaxis struct {
 	graphicsLast     []*placement
 	mouseShapeNext   MouseShape
 	mouseShapeLast   MouseShape
+	regionsNext      []*Region
 	pastePending     bool
 	chClipboard      chan string
+	chColor          chan colorReport
+	chKeyboardFlags  chan KeyboardFlags
 	chSigWinSz       chan os.Signal
 	chSigKill        chan os.Signal
+	chSigStop        chan os.Signal
 	chCursorPos      chan [2]int
 	chQuit           chan bool
 	winSize          Resize
 	nextSize         Resize
 	chSizeDone       chan bool
 	caps             capabilities
+	terminfo         *terminfo
+	colorDepth       ColorDepth
+	palette          []uint32
+	paletteColors    [256]Color
+	cursorColor      Color
+	terminal         TerminalInfo
+	quirks           quirks
 	graphicsProtocol int
 	graphicsIDNext   uint64
 	reqCursorPos     int32
@@ -90,14 +159,74 @@ type Vaxis struct {
 	cursorLast       cursorState
 	closed           bool
 	refresh          bool
-	kittyFlags       int
+	keyboardFlags    []KeyboardFlags
 	disableMouse     bool
+	mouseMode        MouseMode
+	jobControl       bool
+	inline           *inlineState
 
 	renders int
 	elapsed time.Duration
+	stats   FrameStats
+
+	scheduler scheduler
+	clock     clock
+	run       *runState
+
+	escTimeout time.Duration
+	// reading is set while the input parser is running. The parser is
+	// stopped while suspended, so it doesn't steal input from other
+	// programs using the terminal
+	reading int32
+
+	// termMu serializes Render, Suspend and Resume. Job control suspends
+	// and resumes from the input goroutine, while the application renders
+	// from its own. suspended is guarded by termMu
+	termMu    sync.Mutex
+	suspended bool
+
+	// colorQuery serializes color queries, which share chColor
+	colorQuery sync.Mutex
+	// keyboardQuery serializes keyboard flags queries
+	keyboardQuery sync.Mutex
+
+	// regionsLast are the regions of the last frame, and hover is the one
+	// under the pointer. These are guarded by mu, as mouse events are
+	// resolved on the input goroutine
+	regionsLast []*Region
+	hover       *Region
+	pointer     struct {
+		col   int
+		row   int
+		known bool
+	}
+	// capture is the region receiving mouse events while a button is held
+	capture struct {
+		id     any
+		active bool
+	}
 
 	mu     sync.Mutex
 	resize int32
+	// resumed is set when Vaxis resumes, so that the next render always
+	// posts a Resize
+	resumed int32
+	// colorTheme is the last known ColorThemeMode
+	colorTheme int32
+	// colorQueryPending is set while a color query waits for replies
+	colorQueryPending int32
+	// keyboardQueryPending is set while a keyboard flags query waits for
+	// a reply
+	keyboardQueryPending int32
+	// detecting is set while New waits for the replies to the startup
+	// queries
+	detecting int32
+	// mousePixels is set when mouse events are reported in pixels
+	mousePixels int32
+	// cellWidth and cellHeight are the size of a cell in pixels, used to
+	// find the cell of mouse events reported in pixels
+	cellWidth  int32
+	cellHeight int32
 }
 
 // New creates a new [Vaxis] instance. Calling New will query the underlying
@@ -121,20 +250,33 @@ func New(opts Options) (*Vaxis, error) {
 		log.SetOutput(os.Stderr)
 	}
 
+	var err error
+	vx := &Vaxis{}
+
+	vx.terminfo, err = loadTerminfo(os.Getenv("TERM"), os.Getenv)
+	if err != nil {
+		log.Info("[terminfo] %s: %v", os.Getenv("TERM"), err)
+	}
+
 	// Let's give some deadline for our queries responding. If they don't,
 	// it means the terminal doesn't respond to Primary Device Attributes
-	// and that is a problem
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	// and that is a problem. If the terminfo entry says the terminal won't
+	// answer, we don't wait as long. Otherwise a slow connection may just
+	// be delaying the replies
+	timeout := 3 * time.Second
+	if vx.terminfo != nil && !vx.terminfo.answersQueries() {
+		timeout = time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
-	var err error
-	vx := &Vaxis{
-		kittyFlags: 29,
+	if opts.KeyboardFlags == 0 {
+		opts.KeyboardFlags = defaultKeyboardFlags
 	}
-
 	if opts.ReportKeyboardEvents {
-		vx.kittyFlags += 2
+		opts.KeyboardFlags |= KeyboardReportEvents
 	}
+	vx.keyboardFlags = []KeyboardFlags{opts.KeyboardFlags}
 
 	if opts.EventQueueSize < 1 {
 		opts.EventQueueSize = 1024
@@ -143,6 +285,16 @@ func New(opts Options) (*Vaxis, error) {
 	if opts.DisableMouse {
 		vx.disableMouse = true
 	}
+	vx.mouseMode = opts.MouseMode
+
+	vx.jobControl = opts.JobControl
+
+	if opts.Inline {
+		vx.inline = &inlineState{
+			height: opts.InlineHeight,
+			grow:   opts.InlineGrow,
+		}
+	}
 
 	tgts := []*os.File{os.Stderr, os.Stdout, os.Stdin}
 	if opts.WithTTY != "" {
@@ -154,21 +306,30 @@ func New(opts Options) (*Vaxis, error) {
 	}
 
 	vx.queue = make(chan Event, opts.EventQueueSize)
+	vx.events = make(chan Event)
 	vx.screenNext = newScreen()
 	vx.screenLast = newScreen()
 	vx.chClipboard = make(chan string)
+	vx.chColor = make(chan colorReport)
+	vx.chKeyboardFlags = make(chan KeyboardFlags)
 	vx.chSigWinSz = make(chan os.Signal, 1)
 	vx.chSigKill = make(chan os.Signal, 1)
+	vx.chSigStop = make(chan os.Signal, 1)
 	vx.chCursorPos = make(chan [2]int)
 	vx.chQuit = make(chan bool)
 	vx.chSizeDone = make(chan bool, 1)
 	vx.charCache = make(map[string]int, 256)
+	vx.scheduler.interval = frameInterval(opts.MaxFPS)
+	vx.escTimeout = opts.EscTimeout
 	err = vx.openTty(tgts)
 	if err != nil {
 		return nil, err
 	}
 
+	vx.applyTerminfo()
+	atomicStore(&vx.detecting, true)
 	vx.sendQueries()
+	reports := terminalReports{}
 outer:
 	for {
 		select {
@@ -176,7 +337,7 @@ outer:
 			log.Warn("terminal did not respond to DA1 query")
 			break outer
 		case ev := <-vx.queue:
-			switch ev.(type) {
+			switch ev := ev.(type) {
 			case primaryDeviceAttribute:
 				break outer
 			case capabilitySixel:
@@ -194,11 +355,11 @@ outer:
 			case notifyColorChange:
 				log.Info("[capability] Color theme notifications")
 				vx.caps.colorThemeUpdates = true
+			case sgrPixels:
+				log.Info("[capability] SGR-Pixels mouse")
+				vx.caps.sgrPixels = true
 			case kittyKeyboard:
 				log.Info("[capability] Kitty keyboard")
-				if opts.DisableKittyKeyboard {
-					continue
-				}
 				vx.caps.kittyKeyboard = true
 			case styledUnderlines:
 				vx.caps.styledUnderlines = true
@@ -206,6 +367,9 @@ outer:
 			case truecolor:
 				vx.caps.rgb = true
 				log.Info("[capability] RGB")
+			case repeatCharacter:
+				vx.caps.repeatCharacter = true
+				log.Info("[capability] Repeat character")
 			case kittyGraphics:
 				log.Info("[capability] Kitty graphics supported")
 				vx.caps.kittyGraphics = true
@@ -218,16 +382,53 @@ outer:
 			case textAreaChar:
 				vx.caps.reportSizeChars = true
 				log.Info("[capability] Report screen size: characters")
+			case xtversionReport:
+				reports.xtversion = string(ev)
+			case secondaryReport:
+				reports.da2 = ev
+			case tertiaryReport:
+				reports.da3 = string(ev)
+			case backgroundReport:
+				reports.background = Color(ev)
 			}
 		}
 	}
+	atomicStore(&vx.detecting, false)
 
-	vx.enterAltScreen()
+	if opts.DisableKittyKeyboard && opts.Capabilities.KittyKeyboard == CapabilityDetect {
+		opts.Capabilities.KittyKeyboard = CapabilityOff
+	}
+	opts.Capabilities.apply(&vx.caps)
+	vx.colorDepth = detectColorDepth(opts.ColorDepth, vx.caps, os.Getenv)
+	log.Info("[capability] Color depth: %s", vx.colorDepth)
+	if !vx.caps.colorThemeUpdates && reports.background != 0 {
+		mode := themeFromBackground(reports.background)
+		log.Info("[capability] Color theme estimated from background: %d", mode)
+		atomic.StoreInt32(&vx.colorTheme, int32(mode))
+		vx.PostEvent(ColorThemeUpdate{Mode: mode})
+	}
+
+	if opts.MousePixels && vx.caps.sgrPixels && !vx.disableMouse {
+		atomicStore(&vx.mousePixels, true)
+	}
+
+	if vx.inline == nil {
+		vx.enterAltScreen()
+	}
 	vx.enableModes()
 	if !opts.NoSignals {
 		vx.setupSignals()
 	}
-	vx.applyQuirks()
+
+	vx.terminal = identifyTerminal(reports, os.Getenv)
+	log.Info("[terminal] %s", vx.terminal)
+	vx.quirks = lookupQuirks(vx.terminal)
+	if os.Getenv("VAXIS_FORCE_LEGACY_SGR") != "" {
+		vx.quirks.legacySGR = true
+	}
+	if vx.quirks.noGraphics {
+		vx.graphicsProtocol = halfBlock
+	}
 
 	switch os.Getenv("VAXIS_GRAPHICS") {
 	case "none":
@@ -255,36 +456,81 @@ outer:
 	if ws.XPixel == 0 || ws.YPixel == 0 {
 		log.Debug("pixel size not reported, setting graphics protocol to half block")
 		vx.graphicsProtocol = halfBlock
+		if atomicLoad(&vx.mousePixels) {
+			// We can't find the cell of a pixel without the
+			// size of a cell
+			log.Debug("pixel size not reported, disabling SGR-Pixels mouse")
+			atomicStore(&vx.mousePixels, false)
+			_, _ = vx.tw.WriteString(decrst(mouseSGRPixels))
+			_, _ = vx.tw.Flush()
+		}
 	}
-	vx.screenNext.resize(ws.Cols, ws.Rows)
-	vx.screenLast.resize(ws.Cols, ws.Rows)
 	vx.winSize = ws
+	vx.storeCellSize(ws)
+	vx.resizeScreens()
+	if vx.inline != nil {
+		vx.enterInline()
+	}
+	go vx.forwardEvents()
 	vx.PostEvent(vx.winSize)
 	return vx, nil
 }
 
 // PostEvent inserts an event into the [Vaxis] event loop
 func (vx *Vaxis) PostEvent(ev Event) {
+	vx.postEvent(ev)
+}
+
+// postEvent inserts an event into the queue, returning false if the queue was
+// full and the event was dropped
+func (vx *Vaxis) postEvent(ev Event) bool {
 	log.Debug("[event] %#v", ev)
 	select {
 	case vx.queue <- ev:
-		return
+		return true
 	default:
 		log.Warn("Event dropped: %T", ev)
+		return false
 	}
 }
 
-// SyncFunc queues a function to be called from the main thread. vaxis will call
-// the function when the event is received in the main thread either through
-// PollEvent or Events. A Redraw event will be sent to the host application
-// after the function is completed
+// forwardEvents moves events from the queue to the events channel read by the
+// application. The events channel is unbuffered, so we know exactly when the
+// application has received each event. Like the queue it replaces, the events
+// channel is never closed. Forwarding stops when Vaxis is closed: Close posts
+// a QuitEvent first, so an application still reading receives it
+func (vx *Vaxis) forwardEvents() {
+	for {
+		select {
+		case ev := <-vx.queue:
+			select {
+			case vx.events <- ev:
+			case <-vx.chQuit:
+				return
+			}
+			switch ev := ev.(type) {
+			case Tick:
+				ev.Ticker.received()
+			case QuitEvent:
+				return
+			}
+		case <-vx.chQuit:
+			return
+		}
+	}
+}
+
+// SyncFunc queues a function to be called from the main thread. When using
+// [Run], the event loop calls the function and requests a render. Otherwise,
+// the application receives the SyncFunc through PollEvent or Events, and must
+// call it
 func (vx *Vaxis) SyncFunc(fn func()) {
 	vx.PostEvent(SyncFunc(fn))
 }
 
 // PollEvent blocks until there is an Event, and returns that Event
 func (vx *Vaxis) PollEvent() Event {
-	ev, ok := <-vx.queue
+	ev, ok := <-vx.events
 	if !ok {
 		return QuitEvent{}
 	}
@@ -293,7 +539,7 @@ func (vx *Vaxis) PollEvent() Event {
 
 // Events returns the channel of events.
 func (vx *Vaxis) Events() chan Event {
-	return vx.queue
+	return vx.events
 }
 
 // Close shuts down the event loops and returns the terminal to it's original
@@ -304,20 +550,13 @@ func (vx *Vaxis) Close() {
 	}
 	vx.PostEvent(QuitEvent{})
 	vx.closed = true
-	// HACK: The parser could be hanging for input. Because we have a handle
-	// on a real terminal, we can't "actually" close the FD, so the poll
-	// doesn't necessarily wake on the close call. However, we are the only
-	// one reading from it...so we have to do a little dance:
-	// 1. Send a signal that we want to close
-	// 2. Send a DA1 query so there is data on the reader, breaking the read
-	//    loop
-	// 3. Confirm we have closed
-	vx.parser.Close()
-	io.WriteString(vx.console, primaryAttributes)
-	vx.parser.WaitClose()
+	vx.stopReader()
 
 	defer close(vx.chQuit)
 
+	vx.stopScheduler()
+	vx.stopClock()
+
 	vx.Suspend()
 	vx.console.Close()
 
@@ -333,11 +572,17 @@ func (vx *Vaxis) Close() {
 // needed
 func (vx *Vaxis) Resize() {
 	atomicStore(&vx.resize, true)
-	vx.PostEvent(Redraw{})
+	vx.RequestRender()
 }
 
-// Render renders the model's content to the terminal
+// Render renders the model's content to the terminal. Render does nothing
+// while Vaxis is suspended
 func (vx *Vaxis) Render() {
+	vx.termMu.Lock()
+	defer vx.termMu.Unlock()
+	if vx.suspended {
+		return
+	}
 	if atomicLoad(&vx.resize) {
 		defer atomicStore(&vx.resize, false)
 		ws, err := vx.reportWinsize()
@@ -345,25 +590,72 @@ func (vx *Vaxis) Render() {
 			log.Error("couldn't report winsize: %v", err)
 			return
 		}
+		resumed := atomic.SwapInt32(&vx.resumed, 0) == 1
+		vx.storeCellSize(ws)
 		if ws.Cols != vx.winSize.Cols || ws.Rows != vx.winSize.Rows {
-			vx.screenNext.resize(ws.Cols, ws.Rows)
-			vx.screenLast.resize(ws.Cols, ws.Rows)
 			vx.winSize = ws
+			vx.resizeScreens()
+			if vx.inline != nil {
+				vx.resizeInline()
+			}
 			vx.refresh = true
 			vx.PostEvent(vx.winSize)
 			return
 		}
+		if resumed {
+			// Resume always delivers a Resize
+			vx.PostEvent(vx.winSize)
+		}
 	}
 	start := time.Now()
 	// defer renderBuf.Reset()
 	vx.render()
-	_, _ = vx.tw.Flush()
+	n, _ := vx.tw.Flush()
 	// updating cursor state has to be after Flush, we check state change in
 	// flush.
 	vx.cursorLast = vx.cursorNext
-	vx.elapsed += time.Since(start)
+	elapsed := time.Since(start)
+	vx.elapsed += elapsed
 	vx.renders += 1
 	vx.refresh = false
+	vx.mu.Lock()
+	vx.stats.Elapsed = elapsed
+	vx.stats.Bytes = n
+	vx.mu.Unlock()
+}
+
+// FrameStats describes the work done to render a single frame
+type FrameStats struct {
+	// Elapsed is the time taken to render the frame, including writing it
+	// to the terminal
+	Elapsed time.Duration
+	// Rows is the number of rows which were compared cell by cell with the
+	// previous frame
+	Rows int
+	// SkippedRows is the number of rows which were skipped because they
+	// hadn't changed since they were last rendered
+	SkippedRows int
+	// Bytes is the number of bytes written to the terminal
+	Bytes int
+}
+
+// FrameStats returns statistics about the most recently rendered frame
+func (vx *Vaxis) FrameStats() FrameStats {
+	vx.mu.Lock()
+	defer vx.mu.Unlock()
+	return vx.stats
+}
+
+// resizeScreens resizes the screen buffers to the current window size
+func (vx *Vaxis) resizeScreens() {
+	rows := vx.winSize.Rows
+	if vx.inline != nil {
+		rows = vx.inlineRows(rows)
+	}
+	vx.mu.Lock()
+	defer vx.mu.Unlock()
+	vx.screenNext.resize(vx.winSize.Cols, rows)
+	vx.screenLast.resize(vx.winSize.Cols, rows)
 }
 
 // Refresh forces a full render of the entire screen. Traditionally, this should
@@ -379,6 +671,9 @@ func (vx *Vaxis) render() {
 	var (
 		reposition = true
 		cursor     Style
+		sgr        = sgrBuffer{legacy: vx.quirks.legacySGR}
+		depth      = vx.ColorDepth()
+		color      = depth != ColorDepthMonochrome
 	)
 outerLast:
 	// Delete any placements we don't have this round
@@ -407,17 +702,56 @@ outerNew:
 				continue outerNew
 			}
 		}
-		_, _ = vx.tw.WriteString(tparm(cup, p1.row+1, p1.col+1))
+		if vx.inline != nil {
+			// We don't know where the graphic will leave the
+			// cursor, so save and restore it around the write
+			_, _ = vx.tw.WriteString(decsc)
+			_, _ = vx.tw.WriteString(vx.moveCursor(p1.row, p1.col))
+			p1.writeTo(vx.tw)
+			_, _ = vx.tw.WriteString(decrc)
+			continue
+		}
+		_, _ = vx.tw.WriteString(vx.moveCursor(p1.row, p1.col))
 		p1.writeTo(vx.tw)
 	}
 	// Save this frame as the last frame
 	vx.graphicsLast = vx.graphicsNext
-
-	if vx.mouseShapeLast != vx.mouseShapeNext {
-		_, _ = vx.tw.WriteString(tparm(mouseShape, vx.mouseShapeNext))
-		vx.mouseShapeLast = vx.mouseShapeNext
+	// The regions under the pointer may have changed with the frame
+	vx.regionsLast = vx.regionsNext
+	vx.regionsNext = nil
+	vx.updateHover()
+
+	if shape := vx.mouseShape(); vx.mouseShapeLast != shape {
+		_, _ = vx.tw.WriteString(tparm(mouseShape, shape))
+		vx.mouseShapeLast = shape
+	}
+	vx.hashRows(vx.screenNext)
+	// If a block of rows moved vertically, let the terminal scroll them
+	// for us so we only need to draw the rows which were uncovered. The
+	// scrolling region uses absolute rows, and graphics would not be
+	// accounted for, so we only do this in the simple case
+	if !vx.refresh && vx.inline == nil && len(vx.graphicsNext) == 0 {
+		if s, ok := vx.detectScroll(); ok {
+			vx.applyScroll(vx.tw, s)
+		}
+	}
+	rows := len(vx.screenNext.buf)
+	if vx.inline != nil {
+		if vx.inline.grow {
+			vx.reserveInline(vx.inlineContentRows())
+		}
+		rows = vx.inline.reserved
 	}
-	for row := range vx.screenNext.buf {
+	vx.stats = FrameStats{}
+	for row := 0; row < rows; row += 1 {
+		if !vx.refresh && vx.screenNext.hashes[row] == vx.screenLast.hashes[row] {
+			// Nothing in this row has changed since we last
+			// rendered it
+			reposition = true
+			vx.stats.SkippedRows += 1
+			continue
+		}
+		vx.stats.Rows += 1
 		for col := 0; col < len(vx.screenNext.buf[row]); col += 1 {
 			next := vx.screenNext.buf[row][col]
 			if next.sixel {
@@ -425,7 +759,8 @@ outerNew:
 				reposition = true
 				continue
 			}
-			if next == vx.screenLast.buf[row][col] && !vx.refresh {
+			if next == vx.screenLast.buf[row][col] && !vx.refresh &&
+				(reposition || !vx.printGap(row, col, cursor)) {
 				reposition = true
 				// Advance the column by the width of this
 				// character
@@ -442,82 +777,68 @@ outerNew:
 				continue
 			}
 			vx.screenLast.buf[row][col] = next
+			if !color {
+				next.Style = monochrome(next.Style)
+			}
+			// If we didn't reposition at the start of a row, the
+			// terminal may have a wrap pending from the previous row
+			wrapPending := !reposition && col == 0
 			if reposition {
 				if cursor.Hyperlink != "" {
 					_, _ = vx.tw.WriteString(tparm(osc8, "", ""))
 				}
-				_, _ = vx.tw.WriteString(tparm(cup, row+1, col+1))
+				_, _ = vx.tw.WriteString(vx.moveCursor(row, col))
 				reposition = false
 			}
-			// TODO Optimizations
-			// 1. We could save two bytes when both FG and BG change
-			// by combining into a single sequence. It saves one
-			// "\x1b" and one "m". It adds a lot of complexity
-			// though
-			//
-			// 2. We could save some more bytes when FG, BG, and Attr
-			// all change. Lots of complexity to add this
-
-			if cursor.Foreground != next.Foreground {
-				fg := next.Foreground
-				ps := fg.Params()
-				if !vx.caps.rgb {
-					ps = fg.asIndex().Params()
-				}
+
+			if color && cursor.Foreground != next.Foreground {
+				ps := vx.quantize(next.Foreground, depth).Params()
 				switch len(ps) {
 				case 0:
-					_, _ = vx.tw.WriteString(fgReset)
+					sgr.add(fgReset)
 				case 1:
 					switch {
 					case ps[0] < 8:
-						vx.tw.Printf(fgSet, ps[0])
+						sgr.addf(fgSet, ps[0])
 					case ps[0] < 16:
-						vx.tw.Printf(fgBrightSet, ps[0]-8)
+						sgr.addf(fgBrightSet, ps[0]-8)
 					default:
-						vx.tw.Printf(fgIndexSet, ps[0])
+						sgr.addf(fgIndexSet, ps[0])
 					}
 				case 3:
-					vx.tw.Printf(fgRGBSet, ps[0], ps[1], ps[2])
+					sgr.addf(fgRGBSet, ps[0], ps[1], ps[2])
 				}
 			}
 
-			if cursor.Background != next.Background {
-				bg := next.Background
-				ps := bg.Params()
-				if !vx.caps.rgb {
-					ps = bg.asIndex().Params()
-				}
+			if color && cursor.Background != next.Background {
+				ps := vx.quantize(next.Background, depth).Params()
 				switch len(ps) {
 				case 0:
-					_, _ = vx.tw.WriteString(bgReset)
+					sgr.add(bgReset)
 				case 1:
 					switch {
 					case ps[0] < 8:
-						vx.tw.Printf(bgSet, ps[0])
+						sgr.addf(bgSet, ps[0])
 					case ps[0] < 16:
-						vx.tw.Printf(bgBrightSet, ps[0]-8)
+						sgr.addf(bgBrightSet, ps[0]-8)
 					default:
-						vx.tw.Printf(bgIndexSet, ps[0])
+						sgr.addf(bgIndexSet, ps[0])
 					}
 				case 3:
-					vx.tw.Printf(bgRGBSet, ps[0], ps[1], ps[2])
+					sgr.addf(bgRGBSet, ps[0], ps[1], ps[2])
 				}
 			}
 
-			if vx.caps.styledUnderlines {
+			if color && vx.caps.styledUnderlines && !vx.quirks.noUnderlineColor {
 				if cursor.UnderlineColor != next.UnderlineColor {
-					ul := next.UnderlineColor
-					ps := ul.Params()
-					if !vx.caps.rgb {
-						ps = ul.asIndex().Params()
-					}
+					ps := vx.quantize(next.UnderlineColor, depth).Params()
 					switch len(ps) {
 					case 0:
-						_, _ = vx.tw.WriteString(ulColorReset)
+						sgr.add(ulColorReset)
 					case 1:
-						_, _ = vx.tw.Printf(ulIndexSet, ps[0])
+						sgr.addf(ulIndexSet, ps[0])
 					case 3:
-						_, _ = vx.tw.Printf(ulRGBSet, ps[0], ps[1], ps[2])
+						sgr.addf(ulRGBSet, ps[0], ps[1], ps[2])
 					}
 				}
 			}
@@ -531,25 +852,25 @@ outerNew:
 				on := dAttr & next.Attribute
 
 				if on&AttrBold != 0 {
-					_, _ = vx.tw.WriteString(boldSet)
+					sgr.add(boldSet)
 				}
 				if on&AttrDim != 0 {
-					_, _ = vx.tw.WriteString(dimSet)
+					sgr.add(dimSet)
 				}
 				if on&AttrItalic != 0 {
-					_, _ = vx.tw.WriteString(italicSet)
+					sgr.add(italicSet)
 				}
 				if on&AttrBlink != 0 {
-					_, _ = vx.tw.WriteString(blinkSet)
+					sgr.add(blinkSet)
 				}
 				if on&AttrReverse != 0 {
-					_, _ = vx.tw.WriteString(reverseSet)
+					sgr.add(reverseSet)
 				}
 				if on&AttrInvisible != 0 {
-					_, _ = vx.tw.WriteString(hiddenSet)
+					sgr.add(hiddenSet)
 				}
 				if on&AttrStrikethrough != 0 {
-					_, _ = vx.tw.WriteString(strikethroughSet)
+					sgr.add(strikethroughSet)
 				}
 
 				// If the bit is changed and is in previous, it
@@ -557,38 +878,38 @@ outerNew:
 				off := dAttr & attr
 				if off&AttrBold != 0 {
 					// Normal intensity isn't in terminfo
-					_, _ = vx.tw.WriteString(boldDimReset)
+					sgr.add(boldDimReset)
 					// Normal intensity turns off dim. If it
 					// should be on, let's turn it back on
 					if next.Attribute&AttrDim != 0 {
-						_, _ = vx.tw.WriteString(dimSet)
+						sgr.add(dimSet)
 					}
 				}
 				if off&AttrDim != 0 {
 					// Normal intensity isn't in terminfo
-					_, _ = vx.tw.WriteString(boldDimReset)
+					sgr.add(boldDimReset)
 					// Normal intensity turns off bold. If it
 					// should be on, let's turn it back on
 					if next.Attribute&AttrBold != 0 {
-						_, _ = vx.tw.WriteString(boldSet)
+						sgr.add(boldSet)
 					}
 				}
 				if off&AttrItalic != 0 {
-					_, _ = vx.tw.WriteString(italicReset)
+					sgr.add(italicReset)
 				}
 				if off&AttrBlink != 0 {
 					// turn off blink isn't in terminfo
-					_, _ = vx.tw.WriteString(blinkReset)
+					sgr.add(blinkReset)
 				}
 				if off&AttrReverse != 0 {
-					_, _ = vx.tw.WriteString(reverseReset)
+					sgr.add(reverseReset)
 				}
 				if off&AttrInvisible != 0 {
 					// turn off invisible isn't in terminfo
-					_, _ = vx.tw.WriteString(hiddenReset)
+					sgr.add(hiddenReset)
 				}
 				if off&AttrStrikethrough != 0 {
-					_, _ = vx.tw.WriteString(strikethroughReset)
+					sgr.add(strikethroughReset)
 				}
 			}
 
@@ -596,18 +917,20 @@ outerNew:
 				ulStyle := next.UnderlineStyle
 				switch vx.caps.styledUnderlines {
 				case true:
-					_, _ = vx.tw.WriteString(tparm(ulStyleSet, ulStyle))
+					sgr.addf(ulStyleSet, ulStyle)
 				case false:
 					switch ulStyle {
 					case UnderlineOff:
-						_, _ = vx.tw.WriteString(underlineReset)
+						sgr.add(underlineReset)
 					default:
 						// Fallback to single underlines
-						_, _ = vx.tw.WriteString(underlineSet)
+						sgr.add(underlineSet)
 					}
 				}
 			}
 
+			sgr.flush(vx.tw)
+
 			if cursor.Hyperlink != next.Hyperlink {
 				link := next.Hyperlink
 				linkPs := next.HyperlinkParams
@@ -619,6 +942,12 @@ outerNew:
 
 			cursor = next.Style
 
+			if n := vx.eraseRun(row, col, wrapPending); n > 0 {
+				col += n - 1
+				reposition = true
+				continue
+			}
+
 			if next.Width == 0 {
 				next.Width = vx.characterWidth(next.Grapheme)
 			}
@@ -629,6 +958,8 @@ outerNew:
 			default:
 				_, _ = vx.tw.WriteString(next.Grapheme)
 			}
+			vx.advanceInline(next.Width)
+			col += vx.repeatRun(row, col, next)
 			skip := vx.advance(next)
 			for i := 1; i < skip+1; i += 1 {
 				if col+i >= len(vx.screenNext.buf[row]) {
@@ -639,6 +970,7 @@ outerNew:
 			}
 			col += skip
 		}
+		vx.screenLast.hashes[row] = vx.screenNext.hashes[row]
 	}
 	if cursor.Hyperlink != "" {
 		_, _ = vx.tw.WriteString(tparm(osc8, "", ""))
@@ -648,33 +980,24 @@ outerNew:
 	}
 }
 
+// postKey posts a key event. If job control is enabled, Ctrl+Z stops the
+// process instead of being delivered
+func (vx *Vaxis) postKey(key Key) {
+	if vx.pastePending {
+		key.EventType = EventPaste
+	}
+	if vx.jobControl && key.EventType == EventPress && key.Matches('z', ModCtrl) {
+		go vx.stopProcess()
+		return
+	}
+	vx.PostEvent(key)
+}
+
 func (vx *Vaxis) handleSequence(seq ansi.Sequence) {
 	log.Trace("[stdin] sequence: %s", seq)
 	switch seq := seq.(type) {
-	case ansi.Print:
-		key := decodeKey(seq)
-		if vx.pastePending {
-			key.EventType = EventPaste
-		}
-		vx.PostEvent(key)
-	case ansi.C0:
-		key := decodeKey(seq)
-		if vx.pastePending {
-			key.EventType = EventPaste
-		}
-		vx.PostEvent(key)
-	case ansi.ESC:
-		key := decodeKey(seq)
-		if vx.pastePending {
-			key.EventType = EventPaste
-		}
-		vx.PostEvent(key)
-	case ansi.SS3:
-		key := decodeKey(seq)
-		if vx.pastePending {
-			key.EventType = EventPaste
-		}
-		vx.PostEvent(key)
+	case ansi.Print, ansi.C0, ansi.ESC, ansi.SS3:
+		vx.postKey(decodeKey(seq))
 	case ansi.CSI:
 		switch seq.Final {
 		case 'c':
@@ -688,6 +1011,14 @@ func (vx *Vaxis) handleSequence(seq ansi.Sequence) {
 				vx.PostEvent(primaryDeviceAttribute{})
 				return
 			}
+			if len(seq.Intermediate) == 1 && seq.Intermediate[0] == '>' {
+				params := make([]int, 0, len(seq.Parameters))
+				for _, ps := range seq.Parameters {
+					params = append(params, ps[0])
+				}
+				vx.PostEvent(secondaryReport(params))
+				return
+			}
 		case 'I':
 			vx.PostEvent(FocusIn{})
 			return
@@ -734,6 +1065,7 @@ func (vx *Vaxis) handleSequence(seq ansi.Sequence) {
 				switch seq.Parameters[0][0] {
 				case colorThemeResp: // 997
 					m := ColorThemeMode(seq.Parameters[1][0])
+					atomic.StoreInt32(&vx.colorTheme, int32(m))
 					vx.PostEvent(ColorThemeUpdate{
 						Mode: m,
 					})
@@ -774,11 +1106,24 @@ func (vx *Vaxis) handleSequence(seq ansi.Sequence) {
 				case 1, 2:
 					vx.PostEvent(notifyColorChange{})
 				}
+			case 1016:
+				if len(seq.Parameters) < 2 {
+					log.Error("not enough DECRPM params")
+					return
+				}
+				switch seq.Parameters[1][0] {
+				case 1, 2:
+					vx.PostEvent(sgrPixels{})
+				}
 			}
 			return
 		case 'u':
 			if len(seq.Intermediate) == 1 && seq.Intermediate[0] == '?' {
-				vx.PostEvent(kittyKeyboard{})
+				flags := 0
+				if len(seq.Parameters) > 0 {
+					flags = seq.Parameters[0][0]
+				}
+				vx.handleKeyboardFlags(KeyboardFlags(flags))
 				return
 			}
 		case '~':
@@ -801,6 +1146,14 @@ func (vx *Vaxis) handleSequence(seq ansi.Sequence) {
 		case 'M', 'm':
 			mouse, ok := parseMouseEvent(seq)
 			if ok {
+				if atomicLoad(&vx.mousePixels) {
+					mouse = vx.pixelsToCells(mouse)
+				}
+				mouse, ok = vx.inlineMouse(mouse)
+				if !ok {
+					return
+				}
+				mouse = vx.resolveRegion(mouse)
 				vx.PostEvent(mouse)
 			}
 			return
@@ -839,11 +1192,7 @@ func (vx *Vaxis) handleSequence(seq ansi.Sequence) {
 			return
 		}
 
-		key := decodeKey(seq)
-		if vx.pastePending {
-			key.EventType = EventPaste
-		}
-		vx.PostEvent(key)
+		vx.postKey(decodeKey(seq))
 	case ansi.DCS:
 		switch seq.Final {
 		case 'r':
@@ -868,6 +1217,8 @@ func (vx *Vaxis) handleSequence(seq ansi.Sequence) {
 					vx.PostEvent(styledUnderlines{})
 				case hexEncode("RGB"):
 					vx.PostEvent(truecolor{})
+				case hexEncode("rep"):
+					vx.PostEvent(repeatCharacter{})
 				}
 			}
 		case '|':
@@ -876,11 +1227,21 @@ func (vx *Vaxis) handleSequence(seq ansi.Sequence) {
 			}
 			switch seq.Intermediate[0] {
 			case '!':
+				// DA3 response
 				if string(seq.Data) == hexEncode("~VTE") {
 					// VTE supports styled underlines but
 					// doesn't respond to XTGETTCAP
 					vx.PostEvent(styledUnderlines{})
 				}
+				id, err := hex.DecodeString(string(seq.Data))
+				if err != nil {
+					log.Error("error parsing DA3: %s", string(seq.Data))
+					return
+				}
+				vx.PostEvent(tertiaryReport(id))
+			case '>':
+				// XTVERSION response
+				vx.PostEvent(xtversionReport(seq.Data))
 			}
 		}
 	case ansi.APC:
@@ -909,6 +1270,9 @@ func (vx *Vaxis) handleSequence(seq ansi.Sequence) {
 			case <-ctx.Done():
 			}
 		}
+		if reports, ok := parseColorReports(string(seq.Payload)); ok {
+			vx.handleColorReports(reports)
+		}
 	}
 }
 
@@ -926,6 +1290,7 @@ func (vx *Vaxis) sendQueries() {
 	_, _ = vx.tw.WriteString(decrqm(synchronizedUpdate))
 	_, _ = vx.tw.WriteString(decrqm(unicodeCore))
 	_, _ = vx.tw.WriteString(decrqm(colorThemeUpdates))
+	_, _ = vx.tw.WriteString(decrqm(mouseSGRPixels))
 	_, _ = vx.tw.WriteString(xtversion)
 	_, _ = vx.tw.WriteString(kittyKBQuery)
 	_, _ = vx.tw.WriteString(kittyGquery)
@@ -941,9 +1306,15 @@ func (vx *Vaxis) sendQueries() {
 	// dashed, etc), but we'll assume the terminal also suppports underline
 	// colors (CSI 58 : ...)
 	_, _ = vx.tw.WriteString(xtgettcap("Smulx"))
+	// REP lets us write runs of the same character with a single sequence
+	_, _ = vx.tw.WriteString(xtgettcap("rep"))
 	// Need to send tertiary for VTE based terminals. These don't respond to
 	// XTGETTCAP
 	_, _ = vx.tw.WriteString(tertiaryAttributes)
+	_, _ = vx.tw.WriteString(secondaryAttributes)
+	// The background color lets us guess the color theme when the terminal
+	// doesn't support mode 2031
+	_, _ = vx.tw.WriteString(osc11query)
 	// Send Device Attributes is last. Everything responds, and when we get
 	// a response we'll return from init
 	_, _ = vx.tw.WriteString(primaryAttributes)
@@ -952,9 +1323,14 @@ func (vx *Vaxis) sendQueries() {
 
 // enableModes enables all the modes we want
 func (vx *Vaxis) enableModes() {
+	_, _ = vx.tw.WriteString(vx.setColors())
 	// kitty keyboard
 	if vx.caps.kittyKeyboard {
-		_, _ = vx.tw.WriteString(tparm(kittyKBEnable, vx.kittyFlags))
+		_, _ = vx.tw.WriteString(vx.pushKeyboardFlags())
+	} else {
+		// Without kitty keyboard, modifyOtherKeys lets us tell keys
+		// such as Ctrl+i and Tab apart
+		_, _ = vx.tw.WriteString(tparm(modifyOtherKeys, 2))
 	}
 	// sixel scrolling
 	if vx.caps.sixels {
@@ -979,9 +1355,12 @@ func (vx *Vaxis) enableModes() {
 	// TODO: Query for mouse modes or just hope for the best?
 
 	if !vx.disableMouse {
-		_, _ = vx.tw.WriteString(decset(mouseAllEvents))
+		_, _ = vx.tw.WriteString(decset(vx.mouseMode.mode()))
 		_, _ = vx.tw.WriteString(decset(mouseFocusEvents))
 		_, _ = vx.tw.WriteString(decset(mouseSGR))
+		if atomicLoad(&vx.mousePixels) {
+			_, _ = vx.tw.WriteString(decset(mouseSGRPixels))
+		}
 	}
 	_, _ = vx.tw.Flush()
 }
@@ -990,14 +1369,19 @@ func (vx *Vaxis) disableModes() {
 	_, _ = vx.tw.WriteString(sgrReset)               // reset fg, bg, attrs
 	_, _ = vx.tw.WriteString(decrst(bracketedPaste)) // bracketed paste
 	if vx.caps.kittyKeyboard {
-		_, _ = vx.tw.WriteString(kittyKBPop) // kitty keyboard
+		_, _ = vx.tw.WriteString(vx.popKeyboardFlags()) // kitty keyboard
+	} else {
+		_, _ = vx.tw.WriteString(modifyOtherKeysReset)
 	}
 	_, _ = vx.tw.WriteString(decrst(cursorKeys))
 	_, _ = vx.tw.WriteString(numericMode)
 	if !vx.disableMouse {
-		_, _ = vx.tw.WriteString(decrst(mouseAllEvents))
+		_, _ = vx.tw.WriteString(decrst(vx.mouseMode.mode()))
 		_, _ = vx.tw.WriteString(decrst(mouseFocusEvents))
 		_, _ = vx.tw.WriteString(decrst(mouseSGR))
+		if atomicLoad(&vx.mousePixels) {
+			_, _ = vx.tw.WriteString(decrst(mouseSGRPixels))
+		}
 	}
 	if vx.caps.sixels {
 		_, _ = vx.tw.WriteString(decrst(sixelScrolling))
@@ -1010,6 +1394,7 @@ func (vx *Vaxis) disableModes() {
 	}
 	// Most terminals default to "text" mouse shape
 	_, _ = vx.tw.WriteString(tparm(mouseShape, MouseShapeTextInput))
+	_, _ = vx.tw.WriteString(vx.resetColors())
 	_, _ = vx.tw.Flush()
 }
 
@@ -1028,24 +1413,63 @@ func (vx *Vaxis) exitAltScreen() {
 }
 
 // Suspend takes Vaxis out of fullscreen state, disables all terminal modes,
-// stops listening for signals, and returns the terminal to it's original state.
+// stops listening for signals and input, and returns the terminal to it's
+// original state.
 // Suspend can be useful to, for example, drop out of the full screen TUI and
 // run another TUI. The state of vaxis will be retained, so you can reenter the
 // original state by calling Resume
 func (vx *Vaxis) Suspend() error {
+	vx.pauseClock()
+	vx.stopReader()
+	vx.termMu.Lock()
+	defer vx.termMu.Unlock()
+	vx.suspended = true
 	vx.disableModes()
-	vx.exitAltScreen()
+	switch vx.inline {
+	case nil:
+		vx.exitAltScreen()
+	default:
+		vx.exitInline()
+	}
 	signal.Stop(vx.chSigKill)
 	signal.Stop(vx.chSigWinSz)
+	signal.Stop(vx.chSigStop)
 	vx.console.Reset()
 	return nil
 }
 
+// RunExternal suspends Vaxis, runs cmd and waits for it to exit, then resumes
+// Vaxis. Any of the command's Stdin, Stdout and Stderr which are nil are
+// connected to the terminal. RunExternal is useful for running an editor or
+// pager from the application
+func (vx *Vaxis) RunExternal(cmd *exec.Cmd) error {
+	if cmd.Stdin == nil {
+		cmd.Stdin = vx.tty
+	}
+	if cmd.Stdout == nil {
+		cmd.Stdout = vx.tty
+	}
+	if cmd.Stderr == nil {
+		cmd.Stderr = vx.tty
+	}
+	err := vx.Suspend()
+	if err != nil {
+		return err
+	}
+	cmdErr := cmd.Run()
+	err = vx.Resume()
+	if cmdErr != nil {
+		return cmdErr
+	}
+	return err
+}
+
 // makeRaw opens the /dev/tty device, makes it raw, and starts an input parser
 func (vx *Vaxis) openTty(tgts []*os.File) error {
 	for _, s := range tgts {
 		if c, err := console.ConsoleFromFile(s); err == nil {
 			vx.console = c
+			vx.tty = s
 			break
 		}
 	}
@@ -1057,7 +1481,21 @@ func (vx *Vaxis) openTty(tgts []*os.File) error {
 		return err
 	}
 	vx.tw = newWriter(vx)
-	vx.parser = ansi.NewParser(vx.console)
+	vx.startReader()
+	return nil
+}
+
+// startReader starts a new input parser, and the loop handling its sequences
+// and our signals
+func (vx *Vaxis) startReader() {
+	if !atomic.CompareAndSwapInt32(&vx.reading, 0, 1) {
+		return
+	}
+	parser := ansi.NewInputParser(vx.console)
+	if vx.escTimeout > 0 {
+		parser.SetEscTimeout(vx.escTimeout)
+	}
+	vx.parser = parser
 	go func() {
 		defer func() {
 			if err := recover(); err != nil {
@@ -1066,24 +1504,43 @@ func (vx *Vaxis) openTty(tgts []*os.File) error {
 		}()
 		for {
 			select {
-			case seq := <-vx.parser.Next():
+			case seq := <-parser.Next():
 				switch seq := seq.(type) {
 				case ansi.EOF:
 					return
 				default:
 					vx.handleSequence(seq)
-					vx.parser.Finish(seq)
+					parser.Finish(seq)
 				}
 			case <-vx.chSigWinSz:
 				atomicStore(&vx.resize, true)
-				vx.PostEvent(Redraw{})
+				vx.RequestRender()
 			case <-vx.chSigKill:
 				vx.Close()
 				return
+			case <-vx.chSigStop:
+				go vx.stopProcess()
 			}
 		}
 	}()
-	return nil
+}
+
+// stopReader stops the input parser, and waits for it to exit
+func (vx *Vaxis) stopReader() {
+	if !atomic.CompareAndSwapInt32(&vx.reading, 1, 0) {
+		return
+	}
+	// HACK: The parser could be hanging for input. Because we have a handle
+	// on a real terminal, we can't "actually" close the FD, so the poll
+	// doesn't necessarily wake on the close call. However, we are the only
+	// one reading from it...so we have to do a little dance:
+	// 1. Send a signal that we want to close
+	// 2. Send a DA1 query so there is data on the reader, breaking the read
+	//    loop
+	// 3. Confirm we have closed
+	vx.parser.Close()
+	io.WriteString(vx.console, primaryAttributes)
+	vx.parser.WaitClose()
 }
 
 // Resume returns the application to it's fullscreen state, re-enters raw mode,
@@ -1098,10 +1555,22 @@ func (vx *Vaxis) Resume() error {
 	if err != nil {
 		return err
 	}
-	vx.enterAltScreen()
+	vx.startReader()
+	vx.termMu.Lock()
+	switch vx.inline {
+	case nil:
+		vx.enterAltScreen()
+	default:
+		vx.enterInline()
+	}
 	vx.enableModes()
 	vx.setupSignals()
+	vx.suspended = false
+	vx.termMu.Unlock()
+	atomicStore(&vx.resumed, true)
 	atomicStore(&vx.resize, true)
+	vx.resumeClock()
+	vx.RequestRender()
 	return nil
 }
 
@@ -1123,7 +1592,7 @@ func (vx *Vaxis) ShowCursor(col int, row int, style CursorStyle) {
 func (vx *Vaxis) showCursor() string {
 	buf := bytes.NewBuffer(nil)
 	buf.WriteString(vx.cursorStyle())
-	buf.WriteString(tparm(cup, vx.cursorNext.row+1, vx.cursorNext.col+1))
+	buf.WriteString(vx.moveCursor(vx.cursorNext.row, vx.cursorNext.col))
 	buf.WriteString(decset(cursorVisibility))
 	return buf.String()
 }
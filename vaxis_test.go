@@ -0,0 +1,106 @@
+package vaxis_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"git.sr.ht/~rockorager/vaxis"
+	"git.sr.ht/~rockorager/vaxis/vaxistest"
+)
+
+func TestRenderScroll(t *testing.T) {
+	tt, err := vaxistest.New(20, 8, vaxis.Options{})
+	require.NoError(t, err)
+	defer tt.Close()
+
+	draw := func(start int) {
+		win := tt.Vx.Window()
+		win.Clear()
+		win.Println(0, vaxis.Segment{Text: "header"})
+		for row := 1; row < 7; row += 1 {
+			win.Println(row, vaxis.Segment{Text: fmt.Sprintf("line %d", start+row)})
+		}
+		win.Println(7, vaxis.Segment{Text: "footer"})
+		require.NoError(t, tt.Render())
+	}
+	draw(0)
+	for _, start := range []int{1, 3, 2, 0} {
+		draw(start)
+		assert.Equal(t, "header", trimRows(tt.Row(0)))
+		for row := 1; row < 7; row += 1 {
+			assert.Equal(t, fmt.Sprintf("line %d", start+row), trimRows(tt.Row(row)))
+		}
+		assert.Equal(t, "footer", trimRows(tt.Row(7)))
+	}
+}
+
+func TestRenderRuns(t *testing.T) {
+	tt, err := vaxistest.New(30, 3, vaxis.Options{})
+	require.NoError(t, err)
+	defer tt.Close()
+
+	bar := vaxis.Style{Background: vaxis.IndexColor(4)}
+	frames := [][]string{
+		{strings.Repeat("=", 30), "hello world, how are you", strings.Repeat("x", 20) + " end"},
+		{strings.Repeat("-", 12), "hi", strings.Repeat(" ", 21) + "end"},
+		{"", strings.Repeat("*", 30), "a" + strings.Repeat(" ", 28) + "b"},
+	}
+	for _, frame := range frames {
+		win := tt.Vx.Window()
+		win.Clear()
+		for row, line := range frame {
+			win.Println(row, vaxis.Segment{Text: line})
+		}
+		win.New(25, 0, 5, 1).Fill(vaxis.Cell{
+			Character: vaxis.Character{Grapheme: " ", Width: 1},
+			Style:     bar,
+		})
+		require.NoError(t, tt.Render())
+		for row, line := range frame {
+			expected := line + strings.Repeat(" ", 30-len(line))
+			if row == 0 {
+				expected = expected[:25] + "     "
+			}
+			assert.Equal(t, expected, tt.Row(row))
+		}
+		for col := 25; col < 30; col += 1 {
+			assert.Equal(t, bar, tt.Cell(col, 0).Style)
+		}
+		assert.Equal(t, vaxis.Style{}, tt.Cell(24, 0).Style)
+	}
+}
+
+func TestCapabilities(t *testing.T) {
+	// Don't pick up the color depth from the host terminal
+	t.Setenv("TERM", "")
+	t.Setenv("NO_COLOR", "")
+	// The emulator reports RGB support
+	tt, err := vaxistest.New(20, 4, vaxis.Options{})
+	require.NoError(t, err)
+	assert.True(t, tt.Vx.Capabilities().RGB)
+	tt.Close()
+
+	tt, err = vaxistest.New(20, 4, vaxis.Options{
+		Capabilities: vaxis.CapabilityOverrides{
+			RGB:              vaxis.CapabilityOff,
+			StyledUnderlines: vaxis.CapabilityOn,
+		},
+	})
+	require.NoError(t, err)
+	defer tt.Close()
+	caps := tt.Vx.Capabilities()
+	assert.False(t, caps.RGB)
+	assert.True(t, caps.StyledUnderlines)
+
+	// Without RGB, colors are sent as palette indexes
+	tt.Vx.Window().Println(0, vaxis.Segment{
+		Text:  "red",
+		Style: vaxis.Style{Foreground: vaxis.RGBColor(0xff, 0, 0)},
+	})
+	require.NoError(t, tt.Render())
+	assert.Equal(t, vaxis.IndexColor(196), tt.Cell(0, 0).Foreground)
+}
@@ -5,6 +5,7 @@ package vaxis
 import (
 	"fmt"
 	"io"
+	"os"
 	"os/signal"
 	"syscall"
 	"time"
@@ -28,6 +29,45 @@ func (vx *Vaxis) setupSignals() {
 		syscall.SIGSEGV,
 		syscall.SIGTERM,
 	)
+	if vx.jobControl {
+		signal.Notify(vx.chSigStop, syscall.SIGTSTP)
+	}
+}
+
+// stopSelf stops every process in our process group, the same as Ctrl+Z in
+// cooked mode, and returns once we are continued. It is a variable so that
+// tests can replace it without stopping the test binary
+var stopSelf = func() error {
+	cont := make(chan os.Signal, 1)
+	signal.Notify(cont, syscall.SIGCONT)
+	defer signal.Stop(cont)
+	err := unix.Kill(0, unix.SIGTSTP)
+	if err != nil {
+		return err
+	}
+	<-cont
+	return nil
+}
+
+// stopProcess suspends Vaxis and stops the process. Vaxis is resumed when the
+// process is continued. This runs on its own goroutine, Render does nothing
+// until we have resumed
+func (vx *Vaxis) stopProcess() {
+	err := vx.Suspend()
+	if err != nil {
+		log.Error("couldn't suspend: %v", err)
+		return
+	}
+	// Suspend removed our SIGTSTP handler, so stopSelf stops us instead of
+	// notifying us again
+	err = stopSelf()
+	if err != nil {
+		log.Error("couldn't stop process: %v", err)
+	}
+	err = vx.Resume()
+	if err != nil {
+		log.Error("couldn't resume: %v", err)
+	}
 }
 
 // reportWinsize
@@ -28,6 +28,9 @@ func (vx *Vaxis) setupSignals() {
 	go vx.winch()
 }
 
+// stopProcess does nothing, job control is not supported on windows
+func (vx *Vaxis) stopProcess() {}
+
 func (vx *Vaxis) winch() {
 	ticker := time.NewTicker(100 * time.Millisecond)
 	for {
@@ -42,7 +45,7 @@ func (vx *Vaxis) winch() {
 		}
 		if ws.Cols != vx.winSize.Cols || ws.Rows != vx.winSize.Rows {
 			atomicStore(&vx.resize, true)
-			vx.PostEvent(Redraw{})
+			vx.RequestRender()
 		}
 	}
 }
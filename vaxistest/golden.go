@@ -0,0 +1,80 @@
+package vaxistest
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"git.sr.ht/~rockorager/vaxis"
+)
+
+// UpdateEnv is the environment variable which, when set to a non-empty value,
+// causes the golden file helpers to write the current screen to the golden file
+// instead of comparing against it
+const UpdateEnv = "VAXISTEST_UPDATE"
+
+// goldenPath returns the path to the named golden file
+func goldenPath(name string) string {
+	return filepath.Join("testdata", name+".golden")
+}
+
+// StyledString returns the content of the emulated screen with styles encoded
+// as SGR sequences. Each row is encoded independently, so that every row begins
+// with the default style
+func (t *Terminal) StyledString() string {
+	cols, rows := t.Size()
+	out := make([]string, 0, rows)
+	for row := 0; row < rows; row += 1 {
+		cells := make([]vaxis.Cell, 0, cols)
+		for col := 0; col < cols; {
+			cell := t.vt.Cell(col, row)
+			cells = append(cells, cell)
+			if cell.Width < 1 {
+				cell.Width = 1
+			}
+			col += cell.Width
+		}
+		out = append(out, vaxis.EncodeCells(cells))
+	}
+	return strings.Join(out, "\n")
+}
+
+// AssertGolden compares the text content of the emulated screen with the golden
+// file testdata/<name>.golden. If the environment variable VAXISTEST_UPDATE is
+// set, the golden file is written instead
+func (t *Terminal) AssertGolden(tb testing.TB, name string) {
+	tb.Helper()
+	assertGolden(tb, name, t.String())
+}
+
+// AssertGoldenStyled compares the styled content of the emulated screen with
+// the golden file testdata/<name>.golden. See [Terminal.StyledString] for the
+// format of the file
+func (t *Terminal) AssertGoldenStyled(tb testing.TB, name string) {
+	tb.Helper()
+	assertGolden(tb, name, t.StyledString())
+}
+
+func assertGolden(tb testing.TB, name string, actual string) {
+	tb.Helper()
+	path := goldenPath(name)
+	if os.Getenv(UpdateEnv) != "" {
+		err := os.MkdirAll(filepath.Dir(path), 0o755)
+		if err != nil {
+			tb.Fatalf("couldn't create golden directory: %v", err)
+		}
+		err = os.WriteFile(path, []byte(actual), 0o644)
+		if err != nil {
+			tb.Fatalf("couldn't write golden file: %v", err)
+		}
+		return
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		tb.Fatalf("couldn't read golden file (set %s=1 to create it): %v", UpdateEnv, err)
+	}
+	if string(b) != actual {
+		tb.Errorf("screen does not match %s\n--- expected\n%s\n--- actual\n%s", path, string(b), actual)
+	}
+}
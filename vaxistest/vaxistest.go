@@ -0,0 +1,234 @@
+// Package vaxistest provides a headless harness for testing Vaxis applications.
+//
+// A [Terminal] starts a real [vaxis.Vaxis] connected to one side of an
+// in-process pty. The other side of the pty is driven by a [term.Model], which
+// emulates a terminal: it answers the queries Vaxis sends at startup, records
+// everything Vaxis renders, and encodes input events the same way a real
+// terminal would.
+package vaxistest
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/creack/pty"
+
+	"git.sr.ht/~rockorager/vaxis"
+	"git.sr.ht/~rockorager/vaxis/widgets/term"
+)
+
+// Timeout is the amount of time to wait for the emulated terminal to process
+// output before giving up
+var Timeout = 2 * time.Second
+
+// syncPrefix is the prefix of the APC payload used to synchronize with the
+// emulated terminal
+const syncPrefix = "vaxistest;"
+
+// Terminal is a [vaxis.Vaxis] instance running against an emulated terminal
+type Terminal struct {
+	// Vx is the Vaxis instance under test
+	Vx *vaxis.Vaxis
+
+	vt  *term.Model
+	ptm *os.File
+	pts *os.File
+
+	// mu guards the size of the terminal and the sync markers
+	mu     sync.Mutex
+	cols   int
+	rows   int
+	syncID int
+	chSync chan int
+	closed bool
+}
+
+// New starts a [vaxis.Vaxis] with the given options on an emulated terminal of
+// cols x rows. The WithTTY and NoSignals fields of opts are always overridden.
+// Callers must call Close when finished with the Terminal
+func New(cols int, rows int, opts vaxis.Options) (*Terminal, error) {
+	ptm, pts, err := pty.Open()
+	if err != nil {
+		return nil, err
+	}
+	t := &Terminal{
+		vt:     term.New(),
+		ptm:    ptm,
+		pts:    pts,
+		chSync: make(chan int),
+		cols:   cols,
+		rows:   rows,
+	}
+	t.vt.Attach(t.handleEvent)
+	err = t.vt.StartWithPTY(ptm, cols, rows)
+	if err != nil {
+		ptm.Close()
+		pts.Close()
+		return nil, err
+	}
+
+	opts.WithTTY = pts.Name()
+	opts.NoSignals = true
+	t.Vx, err = vaxis.New(opts)
+	if err != nil {
+		t.vt.Close()
+		pts.Close()
+		return nil, err
+	}
+	// Make sure the terminal has seen all of our startup sequences before
+	// handing control to the caller
+	err = t.Sync()
+	if err != nil {
+		t.Close()
+		return nil, err
+	}
+	return t, nil
+}
+
+// handleEvent receives events from the emulated terminal. We only care about
+// our own sync markers
+func (t *Terminal) handleEvent(ev vaxis.Event) {
+	apc, ok := ev.(term.EventAPC)
+	if !ok {
+		return
+	}
+	if !strings.HasPrefix(apc.Payload, syncPrefix) {
+		return
+	}
+	n, err := strconv.Atoi(strings.TrimPrefix(apc.Payload, syncPrefix))
+	if err != nil {
+		return
+	}
+	select {
+	case t.chSync <- n:
+	case <-time.After(Timeout):
+	}
+}
+
+// Sync blocks until the emulated terminal has processed all output written by
+// Vaxis up to this point. Sync works by writing a private APC marker to the
+// pty and waiting for the emulator to report it
+func (t *Terminal) Sync() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.syncID += 1
+	_, err := fmt.Fprintf(t.pts, "\x1b_%s%d\x1b\\", syncPrefix, t.syncID)
+	if err != nil {
+		return err
+	}
+	deadline := time.NewTimer(Timeout)
+	defer deadline.Stop()
+	for {
+		select {
+		case n := <-t.chSync:
+			if n == t.syncID {
+				return nil
+			}
+		case <-deadline.C:
+			return fmt.Errorf("vaxistest: timed out waiting for terminal output")
+		}
+	}
+}
+
+// WriteString writes s directly to the emulated terminal, as if it were
+// output from another program sharing the terminal, and waits for the terminal
+// to process it
+func (t *Terminal) WriteString(s string) error {
+	_, err := t.pts.WriteString(s)
+	if err != nil {
+		return err
+	}
+	return t.Sync()
+}
+
+// Render renders the Vaxis screen and waits for the emulated terminal to
+// process the output
+func (t *Terminal) Render() error {
+	t.Vx.Render()
+	return t.Sync()
+}
+
+// Resize resizes the emulated terminal and notifies Vaxis of the change. A
+// [vaxis.Resize] event will be delivered on the next call to Render
+func (t *Terminal) Resize(cols int, rows int) {
+	t.mu.Lock()
+	t.cols = cols
+	t.rows = rows
+	t.mu.Unlock()
+	t.vt.Resize(cols, rows)
+	t.Vx.Resize()
+}
+
+// Size returns the size of the emulated terminal
+func (t *Terminal) Size() (cols int, rows int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.cols, t.rows
+}
+
+// SendKey encodes the key as the emulated terminal would and writes it to
+// Vaxis' input
+func (t *Terminal) SendKey(key vaxis.Key) {
+	t.vt.Update(key)
+}
+
+// SendMouse encodes the mouse event as the emulated terminal would and writes
+// it to Vaxis' input. The event will only be delivered if Vaxis has enabled
+// mouse reporting
+func (t *Terminal) SendMouse(mouse vaxis.Mouse) {
+	t.vt.Update(mouse)
+}
+
+// SendEvent posts an event directly into the Vaxis event queue, bypassing the
+// emulated terminal
+func (t *Terminal) SendEvent(ev vaxis.Event) {
+	t.Vx.PostEvent(ev)
+}
+
+// NextEvent returns the next event from the Vaxis event queue, or nil if no
+// event is received within the given duration
+func (t *Terminal) NextEvent(timeout time.Duration) vaxis.Event {
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	select {
+	case ev := <-t.Vx.Events():
+		return ev
+	case <-deadline.C:
+		return nil
+	}
+}
+
+// String returns the text content of the emulated screen. Rows are separated
+// by newlines
+func (t *Terminal) String() string {
+	return t.vt.String()
+}
+
+// Row returns the text content of a single row of the emulated screen
+func (t *Terminal) Row(row int) string {
+	rows := strings.Split(t.vt.String(), "\n")
+	if row < 0 || row >= len(rows) {
+		return ""
+	}
+	return rows[row]
+}
+
+// Cell returns the content and style of the emulated screen at col, row
+func (t *Terminal) Cell(col int, row int) vaxis.Cell {
+	return t.vt.Cell(col, row)
+}
+
+// Close shuts down Vaxis and the emulated terminal
+func (t *Terminal) Close() {
+	if t.closed {
+		return
+	}
+	t.closed = true
+	t.Vx.Close()
+	t.vt.Close()
+	t.pts.Close()
+}
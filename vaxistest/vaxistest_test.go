@@ -0,0 +1,107 @@
+package vaxistest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"git.sr.ht/~rockorager/vaxis"
+)
+
+func TestRender(t *testing.T) {
+	tt, err := New(20, 4, vaxis.Options{})
+	require.NoError(t, err)
+	defer tt.Close()
+
+	win := tt.Vx.Window()
+	win.Clear()
+	win.Println(1, vaxis.Segment{
+		Text: "hello",
+		Style: vaxis.Style{
+			Foreground: vaxis.IndexColor(1),
+			Attribute:  vaxis.AttrBold,
+		},
+	}, vaxis.Segment{
+		Text: ", world",
+	})
+	require.NoError(t, tt.Render())
+
+	assert.Equal(t, "hello, world        ", tt.Row(1))
+	cell := tt.Cell(0, 1)
+	assert.Equal(t, "h", cell.Grapheme)
+	assert.Equal(t, vaxis.IndexColor(1), cell.Foreground)
+	assert.Equal(t, vaxis.AttrBold, cell.Attribute)
+	assert.Equal(t, vaxis.Style{}, tt.Cell(5, 1).Style)
+	tt.AssertGolden(t, "render")
+	tt.AssertGoldenStyled(t, "render_styled")
+}
+
+func TestSendKey(t *testing.T) {
+	tt, err := New(20, 4, vaxis.Options{})
+	require.NoError(t, err)
+	defer tt.Close()
+
+	// Drain the initial resize event
+	ev := tt.NextEvent(time.Second)
+	assert.IsType(t, vaxis.Resize{}, ev)
+
+	tt.SendKey(vaxis.Key{Keycode: 'j', Text: "j"})
+	ev = tt.NextEvent(time.Second)
+	key, ok := ev.(vaxis.Key)
+	require.True(t, ok, "expected a key event, got %#v", ev)
+	assert.True(t, key.Matches('j'))
+
+	tt.SendKey(vaxis.Key{Keycode: 'c', Modifiers: vaxis.ModCtrl})
+	ev = tt.NextEvent(time.Second)
+	key, ok = ev.(vaxis.Key)
+	require.True(t, ok, "expected a key event, got %#v", ev)
+	assert.True(t, key.Matches('c', vaxis.ModCtrl))
+}
+
+func TestSendMouse(t *testing.T) {
+	tt, err := New(20, 4, vaxis.Options{})
+	require.NoError(t, err)
+	defer tt.Close()
+
+	tt.NextEvent(time.Second)
+
+	tt.SendMouse(vaxis.Mouse{
+		Button:    vaxis.MouseLeftButton,
+		Col:       3,
+		Row:       2,
+		EventType: vaxis.EventPress,
+	})
+	ev := tt.NextEvent(time.Second)
+	mouse, ok := ev.(vaxis.Mouse)
+	require.True(t, ok, "expected a mouse event, got %#v", ev)
+	assert.Equal(t, 3, mouse.Col)
+	assert.Equal(t, 2, mouse.Row)
+	assert.Equal(t, vaxis.EventPress, mouse.EventType)
+}
+
+func TestResize(t *testing.T) {
+	tt, err := New(20, 4, vaxis.Options{})
+	require.NoError(t, err)
+	defer tt.Close()
+
+	tt.NextEvent(time.Second)
+
+	tt.Resize(30, 5)
+	require.NoError(t, tt.Render())
+	var resize vaxis.Resize
+	for {
+		ev := tt.NextEvent(time.Second)
+		require.NotNil(t, ev, "expected a resize event")
+		if r, ok := ev.(vaxis.Resize); ok {
+			resize = r
+			break
+		}
+	}
+	assert.Equal(t, 30, resize.Cols)
+	assert.Equal(t, 5, resize.Rows)
+	w, h := tt.Vx.Window().Size()
+	assert.Equal(t, 30, w)
+	assert.Equal(t, 5, h)
+}
@@ -1,6 +1,9 @@
 package border
 
-import "git.sr.ht/~rockorager/vaxis"
+import (
+	"git.sr.ht/~rockorager/vaxis"
+	"git.sr.ht/~rockorager/vaxis/theme"
+)
 
 var (
 	horizontal  = vaxis.Character{Grapheme: "─", Width: 1}
@@ -11,7 +14,10 @@ var (
 	bottomLeft  = vaxis.Character{Grapheme: "╰", Width: 1}
 )
 
+// All draws a border around the window and returns the window inside of it.
+// The zero style uses the theme's Border role, as do the other sides
 func All(win vaxis.Window, style vaxis.Style) vaxis.Window {
+	style = theme.Or(win.Vx, style, theme.Border)
 	w, h := win.Size()
 	win.SetCell(0, 0, vaxis.Cell{
 		Character: topLeft,
@@ -53,6 +59,7 @@ func All(win vaxis.Window, style vaxis.Style) vaxis.Window {
 }
 
 func Left(win vaxis.Window, style vaxis.Style) vaxis.Window {
+	style = theme.Or(win.Vx, style, theme.Border)
 	_, h := win.Size()
 	for i := 0; i < h; i += 1 {
 		win.SetCell(0, i, vaxis.Cell{
@@ -64,6 +71,7 @@ func Left(win vaxis.Window, style vaxis.Style) vaxis.Window {
 }
 
 func Right(win vaxis.Window, style vaxis.Style) vaxis.Window {
+	style = theme.Or(win.Vx, style, theme.Border)
 	w, h := win.Size()
 	for i := 0; i < h; i += 1 {
 		win.SetCell(w-1, i, vaxis.Cell{
@@ -75,6 +83,7 @@ func Right(win vaxis.Window, style vaxis.Style) vaxis.Window {
 }
 
 func Bottom(win vaxis.Window, style vaxis.Style) vaxis.Window {
+	style = theme.Or(win.Vx, style, theme.Border)
 	w, h := win.Size()
 	for i := 0; i < w; i += 1 {
 		win.SetCell(i, h-1, vaxis.Cell{
@@ -86,6 +95,7 @@ func Bottom(win vaxis.Window, style vaxis.Style) vaxis.Window {
 }
 
 func Top(win vaxis.Window, style vaxis.Style) vaxis.Window {
+	style = theme.Or(win.Vx, style, theme.Border)
 	w, _ := win.Size()
 	for i := 0; i < w; i += 1 {
 		win.SetCell(i, 0, vaxis.Cell{
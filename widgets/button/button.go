@@ -2,6 +2,7 @@ package button
 
 import (
 	"git.sr.ht/~rockorager/vaxis"
+	"git.sr.ht/~rockorager/vaxis/theme"
 	"git.sr.ht/~rockorager/vaxis/widgets/align"
 )
 
@@ -19,8 +20,10 @@ var FlexWidth SizePolicy = func(w vaxis.Window) vaxis.Window { return w }
 type Model struct {
 	label string
 
-	policy SizePolicy
-	style  vaxis.Style
+	policy     SizePolicy
+	style      vaxis.Style
+	focusStyle *vaxis.Style
+	focused    bool
 
 	onPress func() interface{}
 }
@@ -29,11 +32,20 @@ func New(s string, onPress func() interface{}, sp SizePolicy) *Model {
 	return &Model{label: s, policy: sp, onPress: onPress}
 }
 
+// SetStyle sets the style of the button. By default, the button uses the
+// theme's Primary role
 func (m *Model) SetStyle(style vaxis.Style) *Model {
 	m.style = style
 	return m
 }
 
+// SetFocusStyle sets the style used when the button has focus. By default, the
+// button style is drawn in reverse video
+func (m *Model) SetFocusStyle(style vaxis.Style) *Model {
+	m.focusStyle = &style
+	return m
+}
+
 func (m *Model) Press() interface{} {
 	if m.onPress != nil {
 		return m.onPress()
@@ -41,7 +53,31 @@ func (m *Model) Press() interface{} {
 	return nil
 }
 
+// Focus gives the button focus
+func (m *Model) Focus() {
+	m.focused = true
+}
+
+// Blur removes focus from the button
+func (m *Model) Blur() {
+	m.focused = false
+}
+
+// Update presses the button on Enter or Space, or when it is clicked
 func (m *Model) Update(msg vaxis.Event) {
+	switch msg := msg.(type) {
+	case vaxis.Key:
+		if msg.EventType == vaxis.EventRelease {
+			return
+		}
+		if msg.Matches(vaxis.KeyEnter) || msg.Matches(vaxis.KeySpace) {
+			m.Press()
+		}
+	case vaxis.Mouse:
+		if msg.EventType == vaxis.EventRelease && msg.Button == vaxis.MouseLeftButton {
+			m.Press()
+		}
+	}
 }
 
 func (m *Model) Draw(win vaxis.Window) {
@@ -51,6 +87,16 @@ func (m *Model) Draw(win vaxis.Window) {
 	}
 	win.Clear()
 
+	style := theme.Or(win.Vx, m.style, theme.Primary)
+	if m.focused {
+		switch m.focusStyle {
+		case nil:
+			style.Attribute ^= vaxis.AttrReverse
+		default:
+			style = *m.focusStyle
+		}
+	}
+
 	policyWin := m.policy(win)
 	button := align.TopMiddle(win, policyWin.Width, 1)
 
@@ -59,8 +105,11 @@ func (m *Model) Draw(win vaxis.Window) {
 			Grapheme: " ",
 			Width:    1,
 		},
-		Style: vaxis.Style{Background: m.style.Background},
+		Style: vaxis.Style{
+			Background: style.Background,
+			Attribute:  style.Attribute & vaxis.AttrReverse,
+		},
 	})
 
-	align.TopMiddle(button, len(m.label), 1).Println(0, vaxis.Segment{Text: m.label, Style: m.style})
+	align.TopMiddle(button, len(m.label), 1).Println(0, vaxis.Segment{Text: m.label, Style: style})
 }
@@ -0,0 +1,206 @@
+// Package focus manages keyboard focus between widgets
+package focus
+
+import (
+	"git.sr.ht/~rockorager/vaxis"
+)
+
+// Focusable is a widget which can receive keyboard focus. Focus and Blur are
+// called when the widget gains and loses focus
+type Focusable interface {
+	Update(vaxis.Event)
+	Draw(vaxis.Window)
+	Focus()
+	Blur()
+}
+
+// Ring is an ordered set of widgets, at most one of which has focus. Tab and
+// Shift+Tab move focus forwards and backwards through the ring, and clicking a
+// widget focuses it. Keyboard and paste events are routed only to the focused
+// widget, mouse events are routed to the widget under the mouse.
+//
+// Widgets must be drawn with [Ring.Draw] for mouse events to be routed to them
+type Ring struct {
+	// Global reports if a key is a global shortcut. Global shortcuts are
+	// never delivered to the focused widget, and are left for the
+	// application to handle
+	Global func(vaxis.Key) bool
+
+	widgets []Focusable
+	// windows is the window each widget was last drawn in
+	windows []vaxis.Window
+	focused int
+}
+
+// New creates a ring of the given widgets. The first widget is focused
+func New(widgets ...Focusable) *Ring {
+	r := &Ring{focused: -1}
+	for _, w := range widgets {
+		r.Add(w)
+	}
+	return r
+}
+
+// Add appends a widget to the ring. If no widget has focus, the added widget
+// is focused
+func (r *Ring) Add(w Focusable) {
+	r.widgets = append(r.widgets, w)
+	r.windows = append(r.windows, vaxis.Window{})
+	if r.focused < 0 {
+		r.focus(len(r.widgets) - 1)
+		return
+	}
+	w.Blur()
+}
+
+// Remove removes a widget from the ring. If the widget had focus, focus moves
+// to the next widget
+func (r *Ring) Remove(w Focusable) {
+	i := r.index(w)
+	if i < 0 {
+		return
+	}
+	r.widgets = append(r.widgets[:i], r.widgets[i+1:]...)
+	r.windows = append(r.windows[:i], r.windows[i+1:]...)
+	switch {
+	case i == r.focused:
+		w.Blur()
+		r.focused = -1
+		if len(r.widgets) > 0 {
+			r.focus(i % len(r.widgets))
+		}
+	case i < r.focused:
+		r.focused -= 1
+	}
+}
+
+// Focused returns the focused widget, or nil if the ring is empty
+func (r *Ring) Focused() Focusable {
+	if r.focused < 0 {
+		return nil
+	}
+	return r.widgets[r.focused]
+}
+
+// Focus moves focus to the widget. The widget must be in the ring
+func (r *Ring) Focus(w Focusable) {
+	i := r.index(w)
+	if i < 0 {
+		return
+	}
+	r.focus(i)
+}
+
+// Next moves focus to the next widget, wrapping around to the first
+func (r *Ring) Next() {
+	if len(r.widgets) == 0 {
+		return
+	}
+	r.focus((r.focused + 1) % len(r.widgets))
+}
+
+// Prev moves focus to the previous widget, wrapping around to the last
+func (r *Ring) Prev() {
+	if len(r.widgets) == 0 {
+		return
+	}
+	r.focus((r.focused - 1 + len(r.widgets)) % len(r.widgets))
+}
+
+// Draw draws the widget in the window, and records the window so that mouse
+// events can be routed to the widget
+func (r *Ring) Draw(w Focusable, win vaxis.Window) {
+	i := r.index(w)
+	if i >= 0 {
+		r.windows[i] = win
+	}
+	w.Draw(win)
+}
+
+// Update routes an event. Update returns true if the event was handled by the
+// ring or delivered to a widget. Events which return false, such as global
+// shortcuts or resizes, should be handled by the application
+func (r *Ring) Update(ev vaxis.Event) bool {
+	switch ev := ev.(type) {
+	case vaxis.Key:
+		if r.Global != nil && r.Global(ev) {
+			return false
+		}
+		if ev.EventType != vaxis.EventRelease && ev.EventType != vaxis.EventPaste {
+			switch {
+			case ev.Matches(vaxis.KeyTab):
+				r.Next()
+				return true
+			case ev.Matches(vaxis.KeyTab, vaxis.ModShift):
+				r.Prev()
+				return true
+			}
+		}
+		return r.deliver(ev)
+	case vaxis.PasteStartEvent, vaxis.PasteEndEvent:
+		return r.deliver(ev)
+	case vaxis.Mouse:
+		i := r.widgetAt(ev.Col, ev.Row)
+		if i < 0 {
+			return false
+		}
+		if ev.EventType == vaxis.EventPress && ev.Button == vaxis.MouseLeftButton {
+			r.focus(i)
+		}
+		col, row := r.windows[i].Origin()
+		ev.Col -= col
+		ev.Row -= row
+		r.widgets[i].Update(ev)
+		return true
+	}
+	return false
+}
+
+// deliver sends the event to the focused widget
+func (r *Ring) deliver(ev vaxis.Event) bool {
+	w := r.Focused()
+	if w == nil {
+		return false
+	}
+	w.Update(ev)
+	return true
+}
+
+// focus moves focus to the widget at index i
+func (r *Ring) focus(i int) {
+	if i == r.focused {
+		return
+	}
+	if r.focused >= 0 {
+		r.widgets[r.focused].Blur()
+	}
+	r.focused = i
+	r.widgets[i].Focus()
+}
+
+// index returns the index of the widget in the ring, or -1
+func (r *Ring) index(w Focusable) int {
+	for i, v := range r.widgets {
+		if v == w {
+			return i
+		}
+	}
+	return -1
+}
+
+// widgetAt returns the index of the widget last drawn at col, row, or -1. If
+// windows overlap, the widget later in the ring wins
+func (r *Ring) widgetAt(col int, row int) int {
+	for i := len(r.windows) - 1; i >= 0; i -= 1 {
+		win := r.windows[i]
+		w, h := win.Size()
+		if w <= 0 || h <= 0 {
+			continue
+		}
+		x, y := win.Origin()
+		if col >= x && col < x+w && row >= y && row < y+h {
+			return i
+		}
+	}
+	return -1
+}
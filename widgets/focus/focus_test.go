@@ -0,0 +1,112 @@
+package focus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"git.sr.ht/~rockorager/vaxis"
+)
+
+type widget struct {
+	focused bool
+	events  []vaxis.Event
+}
+
+func (w *widget) Update(ev vaxis.Event) { w.events = append(w.events, ev) }
+func (w *widget) Draw(vaxis.Window)     {}
+func (w *widget) Focus()                { w.focused = true }
+func (w *widget) Blur()                 { w.focused = false }
+
+func TestRingTraversal(t *testing.T) {
+	a, b, c := &widget{}, &widget{}, &widget{}
+	r := New(a, b, c)
+	assert.Equal(t, a, r.Focused())
+	assert.True(t, a.focused)
+	assert.False(t, b.focused)
+
+	assert.True(t, r.Update(vaxis.Key{Keycode: vaxis.KeyTab}))
+	assert.Equal(t, b, r.Focused())
+	assert.False(t, a.focused)
+	assert.True(t, b.focused)
+
+	r.Update(vaxis.Key{Keycode: vaxis.KeyTab})
+	r.Update(vaxis.Key{Keycode: vaxis.KeyTab})
+	assert.Equal(t, a, r.Focused())
+
+	assert.True(t, r.Update(vaxis.Key{Keycode: vaxis.KeyTab, Modifiers: vaxis.ModShift}))
+	assert.Equal(t, c, r.Focused())
+	assert.True(t, c.focused)
+
+	// Traversal keys aren't delivered to widgets
+	assert.Empty(t, a.events)
+	assert.Empty(t, c.events)
+
+	r.Remove(c)
+	assert.False(t, c.focused)
+	assert.Equal(t, a, r.Focused())
+	assert.True(t, a.focused)
+}
+
+func TestRingRouting(t *testing.T) {
+	a, b := &widget{}, &widget{}
+	r := New(a, b)
+	r.Global = func(k vaxis.Key) bool {
+		return k.MatchString("Ctrl+c")
+	}
+
+	key := vaxis.Key{Keycode: 'j', Text: "j"}
+	assert.True(t, r.Update(key))
+	assert.Equal(t, []vaxis.Event{key}, a.events)
+	assert.Empty(t, b.events)
+
+	// Global shortcuts fall through
+	assert.False(t, r.Update(vaxis.Key{Keycode: 'c', Modifiers: vaxis.ModCtrl}))
+	assert.Len(t, a.events, 1)
+
+	// Other events are left for the application
+	assert.False(t, r.Update(vaxis.Resize{}))
+}
+
+func TestRingMouse(t *testing.T) {
+	a, b := &widget{}, &widget{}
+	r := New(a, b)
+	root := vaxis.Window{Width: 20, Height: 10}
+	r.Draw(a, root.New(0, 0, 20, 5))
+	r.Draw(b, root.New(2, 5, 10, 5))
+
+	// Clicking b focuses it, and it receives the click in its own
+	// coordinates
+	assert.True(t, r.Update(vaxis.Mouse{
+		Button:    vaxis.MouseLeftButton,
+		EventType: vaxis.EventPress,
+		Col:       4,
+		Row:       6,
+	}))
+	assert.Equal(t, b, r.Focused())
+	assert.Equal(t, []vaxis.Event{vaxis.Mouse{
+		Button:    vaxis.MouseLeftButton,
+		EventType: vaxis.EventPress,
+		Col:       2,
+		Row:       1,
+	}}, b.events)
+
+	// Scrolling over a is delivered without changing focus
+	assert.True(t, r.Update(vaxis.Mouse{
+		Button:    vaxis.MouseWheelDown,
+		EventType: vaxis.EventPress,
+		Col:       1,
+		Row:       1,
+	}))
+	assert.Equal(t, b, r.Focused())
+	assert.Len(t, a.events, 1)
+
+	// Outside of every widget
+	assert.False(t, r.Update(vaxis.Mouse{
+		Button:    vaxis.MouseLeftButton,
+		EventType: vaxis.EventPress,
+		Col:       15,
+		Row:       8,
+	}))
+	assert.Equal(t, b, r.Focused())
+}
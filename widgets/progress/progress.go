@@ -3,14 +3,17 @@ package progress
 import (
 	"io"
 	"math"
+	"sync"
 
 	"git.sr.ht/~rockorager/vaxis"
+	"git.sr.ht/~rockorager/vaxis/theme"
 )
 
 // Model represents a progress bar. A progress bar is also an io.Reader and an
-// io.Writer. If you pass it a DataMsg with a Total before calling Read or
-// Write, it will pass through the R/W and display the progress
+// io.Writer. If you set a Total before calling Read or Write, it will pass
+// through the R/W and display the progress
 type Model struct {
+	// Style of the bar. The zero style uses the theme's Primary role
 	Style  vaxis.Style
 	Reader io.Reader
 	Writer io.Writer
@@ -18,6 +21,7 @@ type Model struct {
 	Progress float64
 	Total    float64
 	vx       *vaxis.Vaxis
+	mu       sync.Mutex
 }
 
 func New(vx *vaxis.Vaxis) *Model {
@@ -36,6 +40,8 @@ var (
 )
 
 func (m *Model) Draw(win vaxis.Window) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	if m.Total == 0 {
 		return
 	}
@@ -43,72 +49,77 @@ func (m *Model) Draw(win vaxis.Window) {
 	fracBlocks := (m.Progress / m.Total) * float64(w)
 	fullBlocks := math.Floor(fracBlocks)
 	remainder := fracBlocks - fullBlocks
+	style := theme.Or(win.Vx, m.Style, theme.Primary)
 
 	for i := 0; i <= int(fullBlocks); i += 1 {
 		win.SetCell(i, 0, vaxis.Cell{
 			Character: full,
-			Style:     m.Style,
+			Style:     style,
 		})
 	}
 	switch {
 	case remainder >= 0.875:
 		win.SetCell(int(fullBlocks)+1, 0, vaxis.Cell{
 			Character: sevenEighths,
-			Style:     m.Style,
+			Style:     style,
 		})
 	case remainder >= 0.75:
 		win.SetCell(int(fullBlocks)+1, 0, vaxis.Cell{
 			Character: threeFourths,
-			Style:     m.Style,
+			Style:     style,
 		})
 	case remainder >= 0.625:
 		win.SetCell(int(fullBlocks)+1, 0, vaxis.Cell{
 			Character: fiveEighths,
-			Style:     m.Style,
+			Style:     style,
 		})
 	case remainder >= 0.5:
 		win.SetCell(int(fullBlocks)+1, 0, vaxis.Cell{
 			Character: half,
-			Style:     m.Style,
+			Style:     style,
 		})
 	case remainder >= 0.375:
 		win.SetCell(int(fullBlocks)+1, 0, vaxis.Cell{
 			Character: threeEighths,
-			Style:     m.Style,
+			Style:     style,
 		})
 	case remainder >= 0.25:
 		win.SetCell(int(fullBlocks)+1, 0, vaxis.Cell{
 			Character: oneFourth,
-			Style:     m.Style,
+			Style:     style,
 		})
 	case remainder >= 0.125:
 		win.SetCell(int(fullBlocks)+1, 0, vaxis.Cell{
 			Character: oneEighth,
-			Style:     m.Style,
+			Style:     style,
 		})
 	}
 }
 
-// Read counts the bytes read from Reader and sends the Model an updated
-// progress message. The Total field should be set to an expected value for this
-// to work properly
+// Read counts the bytes read from Reader, updates the progress, and requests a
+// render. The Total field should be set to an expected value for this to work
+// properly
 func (m *Model) Read(p []byte) (int, error) {
 	n, err := m.Reader.Read(p)
-	fn := func() {
-		m.Progress = m.Progress + float64(n)
-	}
-	m.vx.PostEvent(fn)
+	m.advance(n)
 	return n, err
 }
 
-// Write counts the bytes written to Writer and sends the Model an updated
-// progress message. The Total field should be set to an expected value for this
-// to work properly
+// Write counts the bytes written to Writer, updates the progress, and requests
+// a render. The Total field should be set to an expected value for this to work
+// properly
 func (m *Model) Write(p []byte) (int, error) {
 	n, err := m.Writer.Write(p)
-	fn := func() {
-		m.Progress = m.Progress + float64(n)
-	}
-	m.vx.PostEvent(fn)
+	m.advance(n)
 	return n, err
 }
+
+func (m *Model) advance(n int) {
+	if n == 0 {
+		return
+	}
+	m.mu.Lock()
+	m.Progress = m.Progress + float64(n)
+	m.mu.Unlock()
+	m.vx.RequestRender()
+}
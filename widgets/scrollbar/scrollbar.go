@@ -1,11 +1,15 @@
 package scrollbar
 
-import "git.sr.ht/~rockorager/vaxis"
+import (
+	"git.sr.ht/~rockorager/vaxis"
+	"git.sr.ht/~rockorager/vaxis/theme"
+)
 
 type Model struct {
 	// The character to display for the bar, defaults to '▐'
 	Character vaxis.Character
-	Style     vaxis.Style
+	// The style of the bar, defaults to the theme's Border role
+	Style vaxis.Style
 
 	// Number of items in the scrolling area
 	TotalHeight int
@@ -39,10 +43,11 @@ func (m *Model) Draw(win vaxis.Window) {
 	if m.Character.Grapheme == "" {
 		m.Character = defaultChar
 	}
+	style := theme.Or(win.Vx, m.Style, theme.Border)
 	for i := 0; i < barH; i += 1 {
 		cell := vaxis.Cell{
 			Character: m.Character,
-			Style:     m.Style,
+			Style:     style,
 		}
 		win.SetCell(0, barTop+i, cell)
 	}
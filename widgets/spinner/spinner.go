@@ -1,24 +1,27 @@
 package spinner
 
 import (
-	"context"
 	"sync"
 	"time"
 
 	"git.sr.ht/~rockorager/vaxis"
+	"git.sr.ht/~rockorager/vaxis/theme"
 )
 
-// Model is a spinner. It has a duration and a set of frames. It will request
-// partial-draws using the last provided Surface at the duration specified
+// Model is a spinner. It has a duration and a set of frames. While spinning,
+// it delivers a [vaxis.Tick] at the duration specified. The frame drawn is
+// based on the time since the spinner was started, so the application only
+// needs to render after each Tick
 type Model struct {
 	Duration time.Duration
 	Frames   []rune
-	Style    vaxis.Style
+	// Style of the spinner. The zero style uses the theme's Primary role
+	Style vaxis.Style
 
-	frame    int
 	mu       sync.Mutex
 	spinning bool
-	cancel   context.CancelFunc
+	started  time.Time
+	ticker   *vaxis.Ticker
 	vx       *vaxis.Vaxis
 }
 
@@ -35,21 +38,22 @@ func (m *Model) Draw(w vaxis.Window) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	if m.spinning {
+		frame := int(time.Since(m.started)/m.Duration) % len(m.Frames)
 		w.SetCell(0, 0, vaxis.Cell{
 			Character: vaxis.Character{
-				Grapheme: string(m.Frames[m.frame]),
+				Grapheme: string(m.Frames[frame]),
 				Width:    1,
 			},
-			Style: m.Style,
+			Style: theme.Or(w.Vx, m.Style, theme.Primary),
 		})
 	}
 }
 
 // Start the spinner. Start is thread safe and non-blocking
 func (m *Model) Start() {
-	m.vx.SyncFunc(func() {
-		m.start()
-	})
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.start()
 }
 
 func (m *Model) start() {
@@ -59,51 +63,35 @@ func (m *Model) start() {
 	if len(m.Frames) == 0 {
 		m.Frames = []rune{'-', '\\', '|', '/'}
 	}
-	var ctx context.Context
-
-	ctx, m.cancel = context.WithCancel(context.Background())
 	m.spinning = true
-	ticker := time.NewTicker(m.Duration)
-	go func() {
-		defer func() {
-			if err := recover(); err != nil {
-				m.vx.Close()
-			}
-		}()
-		for {
-			select {
-			case <-ctx.Done():
-				ticker.Stop()
-				return
-			case <-ticker.C:
-				m.mu.Lock()
-				m.frame = (m.frame + 1) % len(m.Frames)
-				m.vx.PostEvent(vaxis.Redraw{})
-				m.mu.Unlock()
-			}
-		}
-	}()
+	m.started = time.Now()
+	m.ticker = m.vx.Every(m.Duration)
+	m.vx.RequestRender()
 }
 
 // Stop the spinner. Stop is thread safe and non-blocking
 func (m *Model) Stop() {
-	m.vx.SyncFunc(func() {
-		m.stop()
-	})
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.stop()
 }
 
 func (m *Model) stop() {
-	if m.cancel != nil {
-		m.cancel()
+	if m.ticker != nil {
+		m.ticker.Stop()
+		m.ticker = nil
+	}
+	if m.spinning {
+		m.vx.RequestRender()
 	}
 	m.spinning = false
 }
 
-// Toggle the spinner. Stop is thread safe and non-blocking
+// Toggle the spinner. Toggle is thread safe and non-blocking
 func (m *Model) Toggle() {
-	m.vx.SyncFunc(func() {
-		m.toggle()
-	})
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.toggle()
 }
 
 func (m *Model) toggle() {
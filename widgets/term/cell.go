@@ -22,6 +22,28 @@ func (c *cell) rune() string {
 	return c.content
 }
 
+// vaxisCell converts the cell to a [vaxis.Cell]. Empty cells are converted to
+// spaces
+func (c *cell) vaxisCell() vaxis.Cell {
+	var linkPs string
+	if c.urlId != "" {
+		linkPs = "id=" + c.urlId
+	}
+	return vaxis.Cell{
+		Character: vaxis.Character{
+			Grapheme: c.rune(),
+			Width:    c.width,
+		},
+		Style: vaxis.Style{
+			Foreground:      c.fg,
+			Background:      c.bg,
+			Attribute:       c.attrs,
+			Hyperlink:       c.url,
+			HyperlinkParams: linkPs,
+		},
+	}
+}
+
 // Erasing removes characters from the screen without affecting other characters
 // on the screen. Erased characters are lost. The cursor position does not
 // change when erasing characters or lines. Erasing resets the attributes, but
@@ -606,17 +606,14 @@ func (vt *Model) hpr(ps int) {
 //
 // Repeat preceding graphic character Ps times
 func (vt *Model) rep(ps int) {
-	vt.lastCol = false
-	col := vt.cursor.col
-	if col == 0 {
+	if vt.lastGraphic.Grapheme == "" {
 		return
 	}
-	ch := vt.activeScreen[vt.cursor.row][col-1]
+	if ps == 0 {
+		ps = 1
+	}
 	for i := 0; i < ps; i += 1 {
-		if col+column(i) == vt.margin.right {
-			return
-		}
-		vt.activeScreen[vt.cursor.row][vt.cursor.col+column(i)].content = ch.content
+		vt.print(vt.lastGraphic)
 	}
 }
 
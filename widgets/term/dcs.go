@@ -0,0 +1,39 @@
+package term
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// xtgettcap responds to an XTGETTCAP request. The request is a list of hex
+// encoded terminfo capability names, separated by ';'. Each capability is
+// answered in order, with a valid response (1) if we support the capability or
+// an invalid response (0) if we don't
+func (vt *Model) xtgettcap(data string) {
+	for _, name := range strings.Split(data, ";") {
+		b, err := hex.DecodeString(name)
+		if err != nil {
+			fmt.Fprintf(vt.pty, "\x1bP0+r%s\x1b\\", name)
+			continue
+		}
+		var val string
+		switch string(b) {
+		case "RGB":
+			val = "8/8/8"
+		case "rep":
+			val = "%p1%c\\E[%p2%{1}%-b"
+		case "Co", "colors":
+			val = "256"
+		case "TN", "name":
+			val = vt.TERM
+			if val == "" {
+				val = "xterm-256color"
+			}
+		default:
+			fmt.Fprintf(vt.pty, "\x1bP0+r%s\x1b\\", name)
+			continue
+		}
+		fmt.Fprintf(vt.pty, "\x1bP1+r%s=%X\x1b\\", name, val)
+	}
+}
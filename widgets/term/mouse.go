@@ -12,14 +12,10 @@ func (vt *Model) handleMouse(msg vaxis.Mouse) string {
 			// Translate wheel motion into arrows up and down
 			// 3x rows
 			if msg.Button == vaxis.MouseWheelUp {
-				vt.pty.WriteString("\x1bOA")
-				vt.pty.WriteString("\x1bOA")
-				vt.pty.WriteString("\x1bOA")
+				return "\x1bOA\x1bOA\x1bOA"
 			}
 			if msg.Button == vaxis.MouseWheelDown {
-				vt.pty.WriteString("\x1bOB")
-				vt.pty.WriteString("\x1bOB")
-				vt.pty.WriteString("\x1bOB")
+				return "\x1bOB\x1bOB\x1bOB"
 			}
 		}
 		return ""
@@ -28,8 +24,9 @@ func (vt *Model) handleMouse(msg vaxis.Mouse) string {
 	if !vt.mode.mouseMotion && msg.EventType == vaxis.EventMotion && msg.Button == vaxis.MouseNoButton {
 		return ""
 	}
-	// Return early if we aren't reporting drags
-	if !vt.mode.mouseDrag && msg.EventType == vaxis.EventMotion {
+	// Return early if we aren't reporting drags. Any-motion tracking
+	// implies drags are reported
+	if !vt.mode.mouseDrag && !vt.mode.mouseMotion && msg.EventType == vaxis.EventMotion {
 		return ""
 	}
 
@@ -48,6 +48,8 @@ type Model struct {
 	tabStop  []column
 	// lastCol is a flag indicating we printed in the last col
 	lastCol bool
+	// lastGraphic is the last printed character, used by REP
+	lastGraphic ansi.Print
 
 	primaryState cursorState
 	altState     cursorState
@@ -162,6 +164,33 @@ func (vt *Model) StartWithSize(cmd *exec.Cmd, width int, height int) error {
 	}
 
 	vt.resize(width, height)
+	vt.run()
+	return nil
+}
+
+// StartWithPTY starts the terminal using an already opened pty master, rather
+// than starting a command. The caller is responsible for connecting a program
+// to the slave side of the pty. This can be used to emulate a terminal for a
+// program running in the same process, for example in tests
+func (vt *Model) StartWithPTY(ptm *os.File, width int, height int) error {
+	if ptm == nil {
+		return fmt.Errorf("no pty to read from")
+	}
+	vt.pty = ptm
+	err := pty.Setsize(vt.pty, &pty.Winsize{
+		Cols: uint16(width),
+		Rows: uint16(height),
+	})
+	if err != nil {
+		return err
+	}
+	vt.resize(width, height)
+	vt.run()
+	return nil
+}
+
+// run starts the routine which reads from the pty and updates the model
+func (vt *Model) run() {
 	vt.parser = ansi.NewParser(vt.pty)
 	tick := time.NewTicker(8 * time.Millisecond)
 	go func() {
@@ -171,7 +200,10 @@ func (vt *Model) StartWithSize(cmd *exec.Cmd, width int, height int) error {
 			case seq := <-vt.parser.Next():
 				switch seq := seq.(type) {
 				case ansi.EOF:
-					err := cmd.Wait()
+					var err error
+					if vt.cmd != nil {
+						err = vt.cmd.Wait()
+					}
 					vt.eventHandler(EventClosed{
 						Term:  vt,
 						Error: err,
@@ -189,7 +221,6 @@ func (vt *Model) StartWithSize(cmd *exec.Cmd, width int, height int) error {
 			}
 		}
 	}()
-	return nil
 }
 
 // Start starts the terminal with the specified command. Start returns when the
@@ -201,25 +232,35 @@ func (vt *Model) Start(cmd *exec.Cmd) error {
 // Update is called from the host application. This is user input
 func (vt *Model) Update(msg vaxis.Event) {
 	defer atomicStore(&vt.dirty, true)
+	// The modes are changed by the PTY routine, so we hold the lock while
+	// encoding. The write happens without it, so that a full PTY can't
+	// block the routine
+	vt.mu.Lock()
+	str := vt.encodeInput(msg)
+	vt.mu.Unlock()
+	if str != "" {
+		vt.pty.WriteString(str)
+	}
+}
+
+// encodeInput returns the input the event sends to the PTY. vt.mu must be
+// held
+func (vt *Model) encodeInput(msg vaxis.Event) string {
 	switch msg := msg.(type) {
 	case vaxis.Key:
-		str := encodeXterm(msg, vt.mode.deckpam, vt.mode.decckm)
-		vt.pty.WriteString(str)
+		return encodeXterm(msg, vt.mode.deckpam, vt.mode.decckm)
 	case vaxis.PasteStartEvent:
 		if vt.mode.paste {
-			vt.pty.WriteString("\x1B[200~")
-			return
+			return "\x1B[200~"
 		}
 	case vaxis.PasteEndEvent:
 		if vt.mode.paste {
-			vt.pty.WriteString("\x1B[201~")
-			return
+			return "\x1B[201~"
 		}
 	case vaxis.Mouse:
-		mouse := vt.handleMouse(msg)
-		vt.pty.WriteString(mouse)
-		return
+		return vt.handleMouse(msg)
 	}
+	return ""
 }
 
 // update is called from the PTY routine...this is updating the internal model
@@ -244,7 +285,12 @@ func (vt *Model) update(seq ansi.Sequence) {
 		vt.osc(string(seq.Payload))
 	case ansi.DCS:
 		switch seq.Final {
-		case 'q': // sixel
+		case 'q':
+			if len(seq.Intermediate) == 1 && seq.Intermediate[0] == '+' {
+				vt.xtgettcap(string(seq.Data))
+				return
+			}
+			// sixel
 			// Write the raw sequence to the writer
 			buf := bytes.NewBuffer(nil)
 			// DCS
@@ -295,6 +341,20 @@ func (vt *Model) String() string {
 	return str.String()
 }
 
+// Cell returns the content and style of the cell at col, row of the active
+// screen. An empty cell is returned if the location is outside of the screen
+func (vt *Model) Cell(col int, row int) vaxis.Cell {
+	vt.mu.Lock()
+	defer vt.mu.Unlock()
+	if row < 0 || row >= vt.height() {
+		return vaxis.Cell{}
+	}
+	if col < 0 || col >= vt.width() {
+		return vaxis.Cell{}
+	}
+	return vt.activeScreen[row][col].vaxisCell()
+}
+
 func (vt *Model) postEvent(ev vaxis.Event) {
 	vt.events <- ev
 }
@@ -438,6 +498,7 @@ func (vt *Model) print(seq ansi.Print) {
 		}
 		return
 	}
+	vt.lastGraphic = seq
 	cell := cell{
 		content: seq.Grapheme,
 		width:   w,
@@ -524,25 +585,7 @@ func (vt *Model) Draw(win vaxis.Window) {
 		for col := 0; col < vt.width(); {
 			cell := vt.activeScreen[row][col]
 			w := cell.width
-
-			if cell.content == "" {
-				cell.content = " "
-			}
-			vcell := vaxis.Cell{
-				Character: vaxis.Character{
-					Grapheme: cell.content,
-					Width:    cell.width,
-				},
-				Style: vaxis.Style{
-					Foreground:      cell.fg,
-					Background:      cell.bg,
-					Attribute:       cell.attrs,
-					Hyperlink:       cell.url,
-					HyperlinkParams: "id=%s" + cell.urlId,
-				},
-			}
-
-			win.SetCell(col, row, vcell)
+			win.SetCell(col, row, cell.vaxisCell())
 			if w == 0 {
 				w = 1
 			}
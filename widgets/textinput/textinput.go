@@ -5,6 +5,7 @@ import (
 	"unicode"
 
 	"git.sr.ht/~rockorager/vaxis"
+	"git.sr.ht/~rockorager/vaxis/theme"
 	"golang.org/x/exp/slices"
 )
 
@@ -20,10 +21,16 @@ type Model struct {
 	prompt  []vaxis.Character
 
 	Content vaxis.Style
-	Prompt  vaxis.Style
+	// Prompt is the style of the prompt. The zero style uses the theme's
+	// Primary role
+	Prompt vaxis.Style
 	// HideCursor tells the textinput not to draw the cursor
 	HideCursor bool
 
+	// blurred is set when the textinput has lost focus. The cursor is not
+	// drawn while blurred
+	blurred bool
+
 	// invisibleChar, if set, will be displayed instead of the pressed keys
 	invisibleChar vaxis.Character
 
@@ -77,6 +84,16 @@ func (m *Model) String() string {
 	return buf.String()
 }
 
+// Focus gives the textinput focus. A textinput has focus until Blur is called
+func (m *Model) Focus() {
+	m.blurred = false
+}
+
+// Blur removes focus from the textinput, hiding the cursor
+func (m *Model) Blur() {
+	m.blurred = true
+}
+
 func (m *Model) Update(msg vaxis.Event) {
 	switch msg := msg.(type) {
 	case vaxis.PasteEndEvent:
@@ -202,10 +219,11 @@ func (m *Model) Draw(win vaxis.Window) {
 	}
 	win.Clear()
 	col := 0
+	prompt := theme.Or(win.Vx, m.Prompt, theme.Primary)
 	for _, char := range m.prompt {
 		cell := vaxis.Cell{
 			Character: char,
-			Style:     m.Prompt,
+			Style:     prompt,
 		}
 		win.SetCell(col, 0, cell)
 		col += char.Width
@@ -254,7 +272,7 @@ func (m *Model) Draw(win vaxis.Window) {
 			break
 		}
 	}
-	if !m.HideCursor {
+	if !m.HideCursor && !m.blurred {
 		win.ShowCursor(cursor, 0, vaxis.CursorBlock)
 	}
 }
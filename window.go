@@ -141,13 +141,15 @@ func (win Window) Origin() (int, int) {
 }
 
 // Clear fills the Window with spaces with the default colors and removes all
-// graphics placements
+// graphics placements. Mouse regions within the Window are removed, regions
+// registered elsewhere on the screen are kept
 func (win Window) Clear() {
 	// We fill with a \x00 cell to differentiate between eg a text input
 	// space and a cleared cell. \x00 is rendered as a space, but the
 	// internal model will differentiate
 	win.Fill(Cell{Character: Character{" ", 1}, Style: Style{}})
 	win.Vx.graphicsNext = []*placement{}
+	win.clearRegions()
 }
 
 // Print prints [Segment]s, with each block having a given style. Text will be